@@ -0,0 +1,236 @@
+package main
+
+import (
+	"encoding/base64"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/k-kanke/ashiato-backend/pkg/infra/mail"
+	"github.com/k-kanke/ashiato-backend/pkg/repository"
+	"github.com/k-kanke/ashiato-backend/pkg/shared"
+	"github.com/k-kanke/ashiato-backend/pkg/usecase"
+)
+
+// loadTravelPlausibilityConfig は移動速度チェックのしきい値を環境変数で上書きする
+func loadTravelPlausibilityConfig() (usecase.TravelPlausibilityConfig, error) {
+	config := usecase.DefaultTravelPlausibilityConfig()
+
+	if v := os.Getenv("TRAVEL_CHECK_WINDOW"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed <= 0 {
+			return config, fmt.Errorf("invalid TRAVEL_CHECK_WINDOW: %q", v)
+		}
+		config.Window = parsed
+	}
+
+	if v := os.Getenv("TRAVEL_MIN_CHECKED_METERS"); v != "" {
+		parsed, err := strconv.ParseFloat(v, 64)
+		if err != nil || parsed < 0 {
+			return config, fmt.Errorf("invalid TRAVEL_MIN_CHECKED_METERS: %q", v)
+		}
+		config.MinCheckedMeters = parsed
+	}
+
+	speedEnvs := map[string]string{
+		usecase.TravelModeWalk: "TRAVEL_MAX_SPEED_KMH_WALK",
+		usecase.TravelModeCar:  "TRAVEL_MAX_SPEED_KMH_CAR",
+		usecase.TravelModeRail: "TRAVEL_MAX_SPEED_KMH_RAIL",
+		usecase.TravelModeAir:  "TRAVEL_MAX_SPEED_KMH_AIR",
+	}
+	for mode, key := range speedEnvs {
+		v := os.Getenv(key)
+		if v == "" {
+			continue
+		}
+		parsed, err := strconv.ParseFloat(v, 64)
+		if err != nil || parsed <= 0 {
+			return config, fmt.Errorf("invalid %s: %q", key, v)
+		}
+		config.MaxSpeedKmh[mode] = parsed
+	}
+
+	if v := os.Getenv("TRAVEL_DEFAULT_MODE"); v != "" {
+		if _, ok := config.MaxSpeedKmh[v]; !ok {
+			return config, fmt.Errorf("invalid TRAVEL_DEFAULT_MODE: %q", v)
+		}
+		config.DefaultMode = v
+	}
+
+	return config, nil
+}
+
+// loadTokenConfig はアクセストークン・リフレッシュトークンの設定を環境変数から読み込む
+func loadTokenConfig() (usecase.TokenConfig, error) {
+	config := usecase.DefaultTokenConfig()
+
+	if v := os.Getenv("ACCESS_TOKEN_EXPIRY_MINUTES"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed <= 0 {
+			return config, fmt.Errorf("invalid ACCESS_TOKEN_EXPIRY_MINUTES: %q", v)
+		}
+		config.AccessTokenTTL = time.Duration(parsed) * time.Minute
+	}
+
+	if v := os.Getenv("REFRESH_TOKEN_EXPIRY_HOURS"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed <= 0 {
+			return config, fmt.Errorf("invalid REFRESH_TOKEN_EXPIRY_HOURS: %q", v)
+		}
+		config.RefreshTokenTTL = time.Duration(parsed) * time.Hour
+	}
+
+	return config, nil
+}
+
+// loadTokenSigner はアクセストークンの署名鍵を JWT_SIGNING_KEYS_DIR から読み込む
+//
+// ディレクトリ内の <kid>.pem が全て検証用の鍵として JWKS で公開され、
+// JWT_ACTIVE_KEY_ID の鍵で署名する。鍵のローテーションは次の手順で行う:
+//  1. 新しい鍵の PEM を追加してデプロイする（JWKS に公開され、他のサービスのキャッシュに載る）
+//  2. JWT_ACTIVE_KEY_ID を新しい鍵に切り替える
+//  3. アクセストークンの有効期間が過ぎたら、古い鍵を公開鍵のみの PEM に置き換えるか削除する
+//
+// 未設定の場合は起動しない。ローカル開発では JWT_ALLOW_EPHEMERAL_KEY=true で一時的な鍵を使える
+func loadTokenSigner() (*shared.TokenSigner, error) {
+	issuer := os.Getenv("JWT_ISSUER")
+	if issuer == "" {
+		issuer = "ashiato"
+	}
+	audience := os.Getenv("JWT_AUDIENCE")
+	if audience == "" {
+		audience = "ashiato-api"
+	}
+
+	dir := os.Getenv("JWT_SIGNING_KEYS_DIR")
+	if dir == "" {
+		// 設定漏れのまま本番で起動すると、再起動のたびに全員のトークンが無効になり、使い捨ての JWKS が公開されてしまう
+		if os.Getenv("JWT_ALLOW_EPHEMERAL_KEY") != "true" {
+			return nil, fmt.Errorf("JWT_SIGNING_KEYS_DIR not set (set JWT_ALLOW_EPHEMERAL_KEY=true to use an ephemeral key for local development)")
+		}
+		// ローカル開発用。再起動のたびに鍵が変わり、発行済みのアクセストークンは無効になる
+		log.Println("JWT_SIGNING_KEYS_DIR not set; using an ephemeral signing key")
+		key, err := shared.GenerateEd25519SigningKey("ephemeral")
+		if err != nil {
+			return nil, err
+		}
+		keySet, err := shared.NewKeySet(key.KeyID, []*shared.SigningKey{key})
+		if err != nil {
+			return nil, err
+		}
+		return shared.NewTokenSigner(keySet, issuer, audience), nil
+	}
+
+	activeKeyID := os.Getenv("JWT_ACTIVE_KEY_ID")
+	if activeKeyID == "" {
+		return nil, fmt.Errorf("JWT_ACTIVE_KEY_ID not set")
+	}
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWT_SIGNING_KEYS_DIR: %w", err)
+	}
+	keys := make([]*shared.SigningKey, 0, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read signing key: %w", err)
+		}
+		key, err := shared.ParseSigningKeyPEM(strings.TrimSuffix(filepath.Base(path), ".pem"), data)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	keySet, err := shared.NewKeySet(activeKeyID, keys)
+	if err != nil {
+		return nil, err
+	}
+	return shared.NewTokenSigner(keySet, issuer, audience), nil
+}
+
+// loadMailer は MAIL_DRIVER (smtp / file / log) に応じた Mailer を返す
+// 未設定の場合はメールを送らずログに出力する
+func loadMailer() (repository.Mailer, error) {
+	from := os.Getenv("MAIL_FROM")
+	if from == "" {
+		from = "Ashiato <no-reply@localhost>"
+	}
+
+	switch driver := os.Getenv("MAIL_DRIVER"); driver {
+	case "", "log":
+		return mail.NewLogMailer(), nil
+	case "file":
+		dir := os.Getenv("MAIL_DIR")
+		if dir == "" {
+			dir = "./data/mail"
+		}
+		return mail.NewFileMailer(dir, from)
+	case "smtp":
+		port := 587
+		if v := os.Getenv("SMTP_PORT"); v != "" {
+			parsed, err := strconv.Atoi(v)
+			if err != nil || parsed <= 0 {
+				return nil, fmt.Errorf("invalid SMTP_PORT: %q", v)
+			}
+			port = parsed
+		}
+		return mail.NewSMTPMailer(mail.SMTPConfig{
+			Host:     os.Getenv("SMTP_HOST"),
+			Port:     port,
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     from,
+		})
+	default:
+		return nil, fmt.Errorf("invalid MAIL_DRIVER: %q", driver)
+	}
+}
+
+// loadAccountConfig はアカウント管理メールの設定を環境変数で上書きする
+func loadAccountConfig() (usecase.AccountConfig, error) {
+	config := usecase.DefaultAccountConfig()
+
+	if v := os.Getenv("APP_BASE_URL"); v != "" {
+		config.AppBaseURL = v
+	}
+
+	if v := os.Getenv("EMAIL_VERIFICATION_EXPIRY_HOURS"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed <= 0 {
+			return config, fmt.Errorf("invalid EMAIL_VERIFICATION_EXPIRY_HOURS: %q", v)
+		}
+		config.EmailVerificationTTL = time.Duration(parsed) * time.Hour
+	}
+
+	if v := os.Getenv("PASSWORD_RESET_EXPIRY_MINUTES"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed <= 0 {
+			return config, fmt.Errorf("invalid PASSWORD_RESET_EXPIRY_MINUTES: %q", v)
+		}
+		config.PasswordResetTTL = time.Duration(parsed) * time.Minute
+	}
+
+	return config, nil
+}
+
+// loadMFASecretBox は TOTP の秘密鍵を暗号化する鍵を MFA_ENCRYPTION_KEY（32バイトの Base64）から読み込む
+// 鍵を失うと登録済みの二要素認証が使えなくなるため、一時的な鍵は生成しない。
+// 未設定の場合は nil を返し、TOTP の登録・検証を無効にして起動する（リカバリーコードは使える）
+func loadMFASecretBox() (*shared.SecretBox, error) {
+	v := os.Getenv("MFA_ENCRYPTION_KEY")
+	if v == "" {
+		log.Println("MFA_ENCRYPTION_KEY not set; TOTP two-factor authentication is disabled")
+		return nil, nil
+	}
+	key, err := base64.StdEncoding.DecodeString(v)
+	if err != nil {
+		return nil, fmt.Errorf("invalid MFA_ENCRYPTION_KEY: %w", err)
+	}
+	return shared.NewSecretBox(key)
+}
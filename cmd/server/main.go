@@ -3,11 +3,15 @@ package main
 import (
 	"log"
 	"os"
+	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/k-kanke/ashiato-backend/pkg/api"
 	"github.com/k-kanke/ashiato-backend/pkg/api/handler"
+	"github.com/k-kanke/ashiato-backend/pkg/api/middleware"
 	"github.com/k-kanke/ashiato-backend/pkg/infra/database"
+	"github.com/k-kanke/ashiato-backend/pkg/infra/realtime"
+	"github.com/k-kanke/ashiato-backend/pkg/infra/storage"
 	"github.com/k-kanke/ashiato-backend/pkg/usecase"
 )
 
@@ -27,22 +31,110 @@ func main() {
 	}
 	defer dbClient.DB.Close()
 
+	// リアルタイム配信用のハブ（単一ノード前提のインメモリ実装）
+	hub := realtime.NewHub()
+
+	// 認証関連
+	tokenConfig, err := loadTokenConfig()
+	if err != nil {
+		log.Fatalf("Invalid token config: %v", err)
+	}
+	tokenSigner, err := loadTokenSigner()
+	if err != nil {
+		log.Fatalf("Could not load token signing keys: %v", err)
+	}
+	tokenRepo := database.NewTokenRepository(dbClient)
+	authUc := usecase.NewAuthUsecase(tokenRepo, tokenSigner, tokenConfig)
+	authHandler := handler.NewAuthHandler(authUc)
+
 	// User関連
+	mailer, err := loadMailer()
+	if err != nil {
+		log.Fatalf("Invalid mail config: %v", err)
+	}
+	accountConfig, err := loadAccountConfig()
+	if err != nil {
+		log.Fatalf("Invalid account config: %v", err)
+	}
+	mfaSecretBox, err := loadMFASecretBox()
+	if err != nil {
+		log.Fatalf("Invalid MFA config: %v", err)
+	}
 	userRepo := database.NewUserRepository(dbClient)
-	userUc := usecase.NewUserUsecase(userRepo)
+	mfaRepo := database.NewMFARepository(dbClient)
+	mfaUc := usecase.NewMFAUsecase(mfaRepo, userRepo, tokenRepo, authUc, mfaSecretBox)
+	mfaHandler := handler.NewMFAHandler(mfaUc)
+	userUc := usecase.NewUserUsecase(userRepo, tokenRepo, authUc, mfaUc, mailer, accountConfig)
 	userHandler := handler.NewUserHandler(userUc)
 
-	// Pin関連
+	// Notification関連
+	notificationRepo := database.NewNotificationRepository(dbClient)
+	notificationUc := usecase.NewNotificationUsecase(notificationRepo, userRepo, hub)
+	notificationHandler := handler.NewNotificationHandler(notificationUc)
+
+	// Media関連（保存先はローカルディスク）
+	mediaDir := os.Getenv("MEDIA_STORAGE_DIR")
+	if mediaDir == "" {
+		mediaDir = "./data/media"
+	}
+	mediaStorage, err := storage.NewLocalMediaStorage(mediaDir)
+	if err != nil {
+		log.Fatalf("Could not initialize media storage: %v", err)
+	}
+	// 配信時の公開範囲チェックに添付先のピンを参照する
 	pinRepo := database.NewPinRepository(dbClient)
-	pinUc := usecase.NewPinUsecase(pinRepo)
+	mediaRepo := database.NewMediaRepository(dbClient)
+	mediaUc := usecase.NewMediaUsecase(mediaRepo, pinRepo, mediaStorage)
+	mediaHandler := handler.NewMediaHandler(mediaUc)
+
+	// Pin関連
+	audienceRepo := database.NewAudienceRepository(dbClient)
+	travelConfig, err := loadTravelPlausibilityConfig()
+	if err != nil {
+		log.Fatalf("Invalid travel plausibility config: %v", err)
+	}
+	locationVerifier := usecase.NewLocationVerifierChain(
+		usecase.NewMockLocationVerifier(),
+		usecase.NewAccuracyVerifier(200),
+		usecase.NewTravelVerifier(pinRepo, travelConfig),
+	)
+	// 署名鍵が設定されている場合のみ位置トークンを検証する
+	if secret := os.Getenv("LOCATION_TOKEN_SECRET"); secret != "" {
+		required := os.Getenv("LOCATION_TOKEN_REQUIRED") == "true"
+		locationVerifier = usecase.NewLocationVerifierChain(
+			usecase.NewLocationTokenVerifier(secret, required),
+			locationVerifier,
+		)
+	}
+	pinUc := usecase.NewPinUsecase(pinRepo, notificationUc, hub, locationVerifier, audienceRepo, mediaRepo, userRepo)
 	pinHandler := handler.NewPinHandler(pinUc)
 
+	// 公開期限切れのピンを定期的に expired に移す（表示判定は各クエリでも行う）
+	stopPinExpirySweeper := usecase.StartPinExpirySweeper(pinUc, time.Minute)
+	defer stopPinExpirySweeper()
+
+	// Comment関連
+	commentUc := usecase.NewCommentUsecase(pinRepo, notificationUc)
+	commentHandler := handler.NewCommentHandler(commentUc)
+
+	// Reaction関連
+	reactionUc := usecase.NewReactionUsecase(pinRepo, notificationUc)
+	reactionHandler := handler.NewReactionHandler(reactionUc)
+
+	// Stream関連
+	streamUc := usecase.NewStreamUsecase(pinRepo, hub, authUc)
+	streamHandler := handler.NewStreamHandler(streamUc)
+
 	// Friend関連
 	friendRepo := database.NewFriendRepository(dbClient)
-	friendUc := usecase.NewFriendUsecase(friendRepo)
+	friendUc := usecase.NewFriendUsecase(friendRepo, notificationUc)
 	friendHandler := handler.NewFriendHandler(friendUc)
 
-	router := api.SetupRouter(userHandler, pinHandler, friendHandler)
+	// Audience関連
+	audienceUc := usecase.NewAudienceUsecase(audienceRepo, friendRepo)
+	audienceHandler := handler.NewAudienceHandler(audienceUc)
+
+	router := api.SetupRouter(middleware.AuthMiddleware(authUc), authHandler, userHandler, pinHandler, friendHandler, commentHandler, notificationHandler, streamHandler, audienceHandler, reactionHandler, mediaHandler, mfaHandler)
 
 	port := os.Getenv("PORT")
 	if port == "" {
@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/k-kanke/ashiato-backend/pkg/api/middleware"
+	"github.com/k-kanke/ashiato-backend/pkg/usecase"
+)
+
+type AudienceHandler struct {
+	AudienceUsecase usecase.AudienceUsecase
+}
+
+func NewAudienceHandler(uc usecase.AudienceUsecase) *AudienceHandler {
+	return &AudienceHandler{AudienceUsecase: uc}
+}
+
+type CreateAudienceRequest struct {
+	Name      string   `json:"name" binding:"required,max=50"`
+	MemberIDs []string `json:"member_ids"`
+}
+
+func (h *AudienceHandler) CreateAudience(c *gin.Context) {
+	userID := middleware.GetUserIDFromContext(c)
+	var req CreateAudienceRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+
+	audience, err := h.AudienceUsecase.CreateAudience(userID, req.Name, req.MemberIDs)
+	if err != nil {
+		writeAudienceError(c, err, "Failed to create audience")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Audience created successfully", "audience": audience})
+}
+
+func (h *AudienceHandler) GetAudiences(c *gin.Context) {
+	userID := middleware.GetUserIDFromContext(c)
+
+	audiences, err := h.AudienceUsecase.GetAudiences(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve audiences"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"audiences": audiences})
+}
+
+func (h *AudienceHandler) DeleteAudience(c *gin.Context) {
+	userID := middleware.GetUserIDFromContext(c)
+	audienceID := c.Param("audience_id")
+
+	if err := h.AudienceUsecase.DeleteAudience(userID, audienceID); err != nil {
+		writeAudienceError(c, err, "Failed to delete audience")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Audience deleted successfully"})
+}
+
+func (h *AudienceHandler) AddMember(c *gin.Context) {
+	userID := middleware.GetUserIDFromContext(c)
+	audienceID := c.Param("audience_id")
+	memberID := c.Param("user_id")
+
+	audience, err := h.AudienceUsecase.AddMember(userID, audienceID, memberID)
+	if err != nil {
+		writeAudienceError(c, err, "Failed to add audience member")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Audience member added successfully", "audience": audience})
+}
+
+func (h *AudienceHandler) RemoveMember(c *gin.Context) {
+	userID := middleware.GetUserIDFromContext(c)
+	audienceID := c.Param("audience_id")
+	memberID := c.Param("user_id")
+
+	audience, err := h.AudienceUsecase.RemoveMember(userID, audienceID, memberID)
+	if err != nil {
+		writeAudienceError(c, err, "Failed to remove audience member")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Audience member removed successfully", "audience": audience})
+}
+
+func writeAudienceError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, usecase.ErrInvalidAudience), errors.Is(err, usecase.ErrAudienceMemberNotFriend):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, usecase.ErrAudienceNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, usecase.ErrAudienceLimitReached):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
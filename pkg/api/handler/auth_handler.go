@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/k-kanke/ashiato-backend/pkg/api/middleware"
+	"github.com/k-kanke/ashiato-backend/pkg/usecase"
+)
+
+type AuthHandler struct {
+	AuthUsecase usecase.AuthUsecase
+}
+
+func NewAuthHandler(uc usecase.AuthUsecase) *AuthHandler {
+	return &AuthHandler{AuthUsecase: uc}
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+func (h *AuthHandler) RefreshToken(c *gin.Context) {
+	var req RefreshTokenRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+
+	tokens, err := h.AuthUsecase.RefreshTokens(req.RefreshToken)
+	if err != nil {
+		switch {
+		case errors.Is(err, usecase.ErrInvalidRefreshToken),
+			errors.Is(err, usecase.ErrRefreshTokenReused):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh token"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, tokenResponse("Token refreshed successfully", tokens))
+}
+
+func (h *AuthHandler) Logout(c *gin.Context) {
+	claims := middleware.GetTokenClaimsFromContext(c)
+	if claims == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication failed"})
+		return
+	}
+
+	if err := h.AuthUsecase.Logout(claims); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Logout failed"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out successfully"})
+}
+
+func (h *AuthHandler) GetSessions(c *gin.Context) {
+	claims := middleware.GetTokenClaimsFromContext(c)
+	if claims == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication failed"})
+		return
+	}
+
+	sessions, err := h.AuthUsecase.GetSessions(claims.UserID, claims.SessionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve sessions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"sessions": sessions})
+}
+
+func (h *AuthHandler) RevokeSession(c *gin.Context) {
+	userID := middleware.GetUserIDFromContext(c)
+	sessionID := c.Param("session_id")
+
+	if err := h.AuthUsecase.RevokeSession(userID, sessionID); err != nil {
+		if errors.Is(err, usecase.ErrSessionNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke session"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Session revoked successfully"})
+}
+
+// RevokeAllSessions は現在の端末も含め、全ての端末からログアウトする
+func (h *AuthHandler) RevokeAllSessions(c *gin.Context) {
+	userID := middleware.GetUserIDFromContext(c)
+
+	if err := h.AuthUsecase.RevokeAllSessions(userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke sessions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out of all sessions successfully"})
+}
+
+// GetJWKS は他のサービスが Ashiato のアクセストークンを検証するための公開鍵を返す
+func (h *AuthHandler) GetJWKS(c *gin.Context) {
+	// 鍵のローテーションが反映されるよう、キャッシュは短めにする
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.AuthUsecase.GetJWKS())
+}
+
+// clientInfo はセッション一覧に表示する端末情報をリクエストから取り出す
+func clientInfo(c *gin.Context) usecase.ClientInfo {
+	return usecase.ClientInfo{
+		UserAgent: c.Request.UserAgent(),
+		IPAddress: c.ClientIP(),
+	}
+}
+
+// tokenResponse は既存クライアントとの互換のため、アクセストークンを "token" にも入れて返す
+func tokenResponse(message string, tokens *usecase.AuthTokens) gin.H {
+	return gin.H{
+		"message":       message,
+		"token":         tokens.AccessToken,
+		"access_token":  tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    tokens.ExpiresIn,
+	}
+}
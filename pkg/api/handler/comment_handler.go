@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/k-kanke/ashiato-backend/pkg/api/middleware"
+	"github.com/k-kanke/ashiato-backend/pkg/usecase"
+)
+
+type CommentHandler struct {
+	CommentUsecase usecase.CommentUsecase
+}
+
+func NewCommentHandler(uc usecase.CommentUsecase) *CommentHandler {
+	return &CommentHandler{CommentUsecase: uc}
+}
+
+type CreateCommentRequest struct {
+	ContentText string `json:"content_text" binding:"required,max=1000"`
+}
+
+type GetCommentsRequest struct {
+	Cursor string `form:"cursor"`
+	Limit  int    `form:"limit"`
+}
+
+func (h *CommentHandler) CreateComment(c *gin.Context) {
+	userID := middleware.GetUserIDFromContext(c)
+	pinID := c.Param("pin_id")
+	var req CreateCommentRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+
+	comment, err := h.CommentUsecase.PostComment(userID, pinID, req.ContentText)
+	if err != nil {
+		writeCommentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Comment created successfully", "comment": comment})
+}
+
+func (h *CommentHandler) GetComments(c *gin.Context) {
+	userID := middleware.GetUserIDFromContext(c)
+	pinID := c.Param("pin_id")
+	var req GetCommentsRequest
+
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters"})
+		return
+	}
+
+	comments, nextCursor, err := h.CommentUsecase.GetComments(userID, pinID, req.Cursor, req.Limit)
+	if err != nil {
+		writeCommentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"comments": comments, "next_cursor": nextCursor})
+}
+
+func (h *CommentHandler) DeleteComment(c *gin.Context) {
+	userID := middleware.GetUserIDFromContext(c)
+	pinID := c.Param("pin_id")
+	commentID := c.Param("comment_id")
+
+	if err := h.CommentUsecase.DeleteComment(userID, pinID, commentID); err != nil {
+		writeCommentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Comment deleted successfully"})
+}
+
+func writeCommentError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrPinNotFound), errors.Is(err, usecase.ErrCommentNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, usecase.ErrCommentForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, usecase.ErrEmptyComment), errors.Is(err, usecase.ErrInvalidPageCursor):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process comment"})
+	}
+}
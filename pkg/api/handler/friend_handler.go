@@ -1,10 +1,12 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/k-kanke/ashiato-backend/pkg/api/middleware"
+	"github.com/k-kanke/ashiato-backend/pkg/domain"
 	"github.com/k-kanke/ashiato-backend/pkg/usecase"
 )
 
@@ -21,7 +23,7 @@ func (h *FriendHandler) RequestFriendship(c *gin.Context) {
 	targetID := c.Param("user_id")
 
 	if err := h.FriendUsecase.RequestFriendship(requesterID, targetID); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeFriendError(c, err)
 		return
 	}
 
@@ -33,21 +35,149 @@ func (h *FriendHandler) AcceptFriendship(c *gin.Context) {
 	targetID := c.Param("user_id")
 
 	if err := h.FriendUsecase.AcceptFriendship(accepterID, targetID); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeFriendError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Friend request accepted successfully"})
 }
 
+func (h *FriendHandler) RejectFriendship(c *gin.Context) {
+	rejecterID := middleware.GetUserIDFromContext(c)
+	targetID := c.Param("user_id")
+
+	if err := h.FriendUsecase.RejectFriendship(rejecterID, targetID); err != nil {
+		writeFriendError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Friend request rejected successfully"})
+}
+
+func (h *FriendHandler) CancelFriendship(c *gin.Context) {
+	requesterID := middleware.GetUserIDFromContext(c)
+	targetID := c.Param("user_id")
+
+	if err := h.FriendUsecase.CancelFriendship(requesterID, targetID); err != nil {
+		writeFriendError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Friend request cancelled successfully"})
+}
+
+func (h *FriendHandler) Unfriend(c *gin.Context) {
+	userID := middleware.GetUserIDFromContext(c)
+	targetID := c.Param("user_id")
+
+	if err := h.FriendUsecase.Unfriend(userID, targetID); err != nil {
+		writeFriendError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Friend removed successfully"})
+}
+
+func (h *FriendHandler) BlockUser(c *gin.Context) {
+	blockerID := middleware.GetUserIDFromContext(c)
+	targetID := c.Param("user_id")
+
+	if err := h.FriendUsecase.BlockUser(blockerID, targetID); err != nil {
+		writeFriendError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User blocked successfully"})
+}
+
+func (h *FriendHandler) UnblockUser(c *gin.Context) {
+	blockerID := middleware.GetUserIDFromContext(c)
+	targetID := c.Param("user_id")
+
+	if err := h.FriendUsecase.UnblockUser(blockerID, targetID); err != nil {
+		writeFriendError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User unblocked successfully"})
+}
+
+type GetFriendsListRequest struct {
+	Cursor string `form:"cursor"`
+	Limit  int    `form:"limit"`
+}
+
 func (h *FriendHandler) GetFriendsList(c *gin.Context) {
+	h.listFriendSummaries(c, "friends", h.FriendUsecase.GetFriendsList)
+}
+
+func (h *FriendHandler) GetIncomingRequests(c *gin.Context) {
+	h.listFriendSummaries(c, "requests", h.FriendUsecase.GetIncomingRequests)
+}
+
+func (h *FriendHandler) GetOutgoingRequests(c *gin.Context) {
+	h.listFriendSummaries(c, "requests", h.FriendUsecase.GetOutgoingRequests)
+}
+
+func (h *FriendHandler) listFriendSummaries(
+	c *gin.Context,
+	key string,
+	list func(userID, cursor string, limit int) ([]domain.FriendSummary, string, error),
+) {
 	userID := middleware.GetUserIDFromContext(c)
+	var req GetFriendsListRequest
+
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters"})
+		return
+	}
 
-	friendIDs, err := h.FriendUsecase.GetFriendsList(userID)
+	summaries, nextCursor, err := list(userID, req.Cursor, req.Limit)
 	if err != nil {
+		if errors.Is(err, usecase.ErrInvalidPageCursor) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"friends": friendIDs})
+	c.JSON(http.StatusOK, gin.H{key: summaries, "next_cursor": nextCursor})
+}
+
+type GetFriendSuggestionsRequest struct {
+	Limit int `form:"limit"`
+}
+
+func (h *FriendHandler) GetFriendSuggestions(c *gin.Context) {
+	userID := middleware.GetUserIDFromContext(c)
+	var req GetFriendSuggestionsRequest
+
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters"})
+		return
+	}
+
+	suggestions, err := h.FriendUsecase.GetFriendSuggestions(userID, req.Limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve friend suggestions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"suggestions": suggestions})
+}
+
+func writeFriendError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrInvalidFriendTarget):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, usecase.ErrFriendshipNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, usecase.ErrAlreadyFriends),
+		errors.Is(err, usecase.ErrFriendRequestPending),
+		errors.Is(err, usecase.ErrInvalidFriendshipTransition):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update friendship"})
+	}
 }
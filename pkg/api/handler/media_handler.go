@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/k-kanke/ashiato-backend/pkg/api/middleware"
+	"github.com/k-kanke/ashiato-backend/pkg/usecase"
+)
+
+type MediaHandler struct {
+	MediaUsecase usecase.MediaUsecase
+}
+
+func NewMediaHandler(uc usecase.MediaUsecase) *MediaHandler {
+	return &MediaHandler{MediaUsecase: uc}
+}
+
+// multipart のヘッダー分の余裕を持たせたリクエスト全体の上限
+const maxMediaRequestBytes = usecase.MaxMediaBytes + 1<<20
+
+func (h *MediaHandler) UploadMedia(c *gin.Context) {
+	userID := middleware.GetUserIDFromContext(c)
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxMediaRequestBytes)
+
+	file, _, err := c.Request.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file is required (max 10MB)"})
+		return
+	}
+	defer file.Close()
+
+	item, err := h.MediaUsecase.UploadMedia(userID, file)
+	if err != nil {
+		switch {
+		case errors.Is(err, usecase.ErrUnsupportedMediaType):
+			c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": err.Error()})
+		case errors.Is(err, usecase.ErrMediaTooLarge):
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+		case errors.Is(err, usecase.ErrInvalidMedia):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to upload media"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Media uploaded successfully", "media": item})
+}
+
+func (h *MediaHandler) GetMedia(c *gin.Context) {
+	h.serveMedia(c, usecase.MediaVariantOriginal)
+}
+
+func (h *MediaHandler) GetMediaThumbnail(c *gin.Context) {
+	h.serveMedia(c, usecase.MediaVariantThumbnail)
+}
+
+func (h *MediaHandler) serveMedia(c *gin.Context, variant string) {
+	userID := middleware.GetUserIDFromContext(c)
+	mediaID := c.Param("media_id")
+
+	file, contentType, err := h.MediaUsecase.OpenMedia(userID, mediaID, variant)
+	if err != nil {
+		if errors.Is(err, usecase.ErrMediaNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve media"})
+		return
+	}
+	defer file.Close()
+
+	// ピンの公開範囲の変更がすぐ反映されるよう、共有キャッシュには載せず短時間だけキャッシュさせる
+	c.Header("Cache-Control", "private, max-age=300")
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Header("Content-Type", contentType)
+	c.Status(http.StatusOK)
+	if _, err := io.Copy(c.Writer, file); err != nil {
+		log.Printf("failed to write media %s: %v", mediaID, err)
+	}
+}
@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/k-kanke/ashiato-backend/pkg/api/middleware"
+	"github.com/k-kanke/ashiato-backend/pkg/usecase"
+)
+
+type MFAHandler struct {
+	MFAUsecase usecase.MFAUsecase
+}
+
+func NewMFAHandler(uc usecase.MFAUsecase) *MFAHandler {
+	return &MFAHandler{MFAUsecase: uc}
+}
+
+type MFALoginRequest struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+	Code     string `json:"code" binding:"required"` // TOTP のコードまたはリカバリーコード
+}
+
+func (h *MFAHandler) CompleteLogin(c *gin.Context) {
+	var req MFALoginRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+
+	tokens, err := h.MFAUsecase.CompleteChallenge(req.MFAToken, req.Code, clientInfo(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, usecase.ErrInvalidMFAChallenge),
+			errors.Is(err, usecase.ErrInvalidMFACode):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		case errors.Is(err, usecase.ErrMFALocked):
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+		case errors.Is(err, usecase.ErrMFAUnavailable):
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Login failed"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, tokenResponse("Login successful", tokens))
+}
+
+func (h *MFAHandler) BeginTOTPEnrollment(c *gin.Context) {
+	userID := middleware.GetUserIDFromContext(c)
+
+	enrollment, err := h.MFAUsecase.BeginTOTPEnrollment(userID)
+	if err != nil {
+		writeMFAError(c, err, "Failed to start two-factor authentication enrollment")
+		return
+	}
+
+	c.JSON(http.StatusOK, enrollment)
+}
+
+type MFACodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+func (h *MFAHandler) ConfirmTOTPEnrollment(c *gin.Context) {
+	userID := middleware.GetUserIDFromContext(c)
+	var req MFACodeRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+
+	codes, err := h.MFAUsecase.ConfirmTOTPEnrollment(userID, req.Code)
+	if err != nil {
+		writeMFAError(c, err, "Failed to enable two-factor authentication")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication enabled", "recovery_codes": codes})
+}
+
+func (h *MFAHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	userID := middleware.GetUserIDFromContext(c)
+	var req MFACodeRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+
+	codes, err := h.MFAUsecase.RegenerateRecoveryCodes(userID, req.Code)
+	if err != nil {
+		writeMFAError(c, err, "Failed to regenerate recovery codes")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Recovery codes regenerated", "recovery_codes": codes})
+}
+
+func (h *MFAHandler) DisableMFA(c *gin.Context) {
+	userID := middleware.GetUserIDFromContext(c)
+	var req MFACodeRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+
+	if err := h.MFAUsecase.DisableMFA(userID, req.Code); err != nil {
+		writeMFAError(c, err, "Failed to disable two-factor authentication")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication disabled"})
+}
+
+func writeMFAError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, usecase.ErrInvalidMFACode):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, usecase.ErrMFAAlreadyEnabled),
+		errors.Is(err, usecase.ErrMFANotEnabled),
+		errors.Is(err, usecase.ErrMFANotEnrolled):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, usecase.ErrMFALocked):
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+	case errors.Is(err, usecase.ErrMFAUnavailable):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/k-kanke/ashiato-backend/pkg/api/middleware"
+	"github.com/k-kanke/ashiato-backend/pkg/usecase"
+)
+
+type NotificationHandler struct {
+	NotificationUsecase usecase.NotificationUsecase
+}
+
+func NewNotificationHandler(uc usecase.NotificationUsecase) *NotificationHandler {
+	return &NotificationHandler{NotificationUsecase: uc}
+}
+
+type GetNotificationsRequest struct {
+	Cursor string `form:"cursor"`
+	Limit  int    `form:"limit"`
+}
+
+func (h *NotificationHandler) GetNotifications(c *gin.Context) {
+	userID := middleware.GetUserIDFromContext(c)
+	var req GetNotificationsRequest
+
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters"})
+		return
+	}
+
+	notifications, nextCursor, err := h.NotificationUsecase.GetNotifications(userID, req.Cursor, req.Limit)
+	if err != nil {
+		if errors.Is(err, usecase.ErrInvalidPageCursor) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve notifications"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"notifications": notifications, "next_cursor": nextCursor})
+}
+
+func (h *NotificationHandler) GetUnreadCount(c *gin.Context) {
+	userID := middleware.GetUserIDFromContext(c)
+
+	count, err := h.NotificationUsecase.GetUnreadCount(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count notifications"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"unread_count": count})
+}
+
+func (h *NotificationHandler) MarkAsRead(c *gin.Context) {
+	userID := middleware.GetUserIDFromContext(c)
+	notificationID := c.Param("notification_id")
+
+	if err := h.NotificationUsecase.MarkAsRead(userID, notificationID); err != nil {
+		if errors.Is(err, usecase.ErrNotificationNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update notification"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Notification marked as read"})
+}
+
+func (h *NotificationHandler) MarkAllAsRead(c *gin.Context) {
+	userID := middleware.GetUserIDFromContext(c)
+
+	if err := h.NotificationUsecase.MarkAllAsRead(userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update notifications"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "All notifications marked as read"})
+}
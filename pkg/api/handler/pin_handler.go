@@ -1,11 +1,20 @@
 package handler
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
+	"fmt"
+	"log"
+	"math"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/k-kanke/ashiato-backend/pkg/api/middleware"
+	"github.com/k-kanke/ashiato-backend/pkg/shared/geofile"
 	"github.com/k-kanke/ashiato-backend/pkg/usecase"
 )
 
@@ -18,11 +27,26 @@ func NewPinHandler(uc usecase.PinUsecase) *PinHandler {
 }
 
 type CreatePinRequest struct {
-	Latitude       float64 `json:"latitude" binding:"required"`
-	Longitude      float64 `json:"longitude" binding:"required"`
-	ContentText    string  `json:"content_text" binding:"required"`
-	MediaURL       string  `json:"media_url"`
-	PrivacySetting string  `json:"privacy_setting" binding:"required,oneof=public friends"`
+	Latitude       float64    `json:"latitude" binding:"required"`
+	Longitude      float64    `json:"longitude" binding:"required"`
+	ContentText    string     `json:"content_text" binding:"required"`
+	MediaID        string     `json:"media_id"` // POST /v1/media で発行したメディアID
+	PrivacySetting string     `json:"privacy_setting" binding:"required,oneof=public friends private audience"`
+	AudienceID     string     `json:"audience_id"`      // privacy_setting が audience の場合に必須
+	AccuracyMeters float64    `json:"accuracy_m"`       // 端末が報告した測位精度
+	IsMockLocation bool       `json:"is_mock_location"` // 擬似ロケーションが有効か
+	LocationToken  string     `json:"location_token"`   // 信頼済みSDKが発行した署名付き位置トークン
+	TravelMode     string     `json:"travel_mode" binding:"omitempty,oneof=walk car rail air"`
+	ExpiresIn      *int       `json:"expires_in" binding:"omitempty,min=300,max=2592000"` // 公開期間（秒、5分〜30日）。expires_at とは同時に指定できない
+	ExpiresAt      *time.Time `json:"expires_at"`                                         // 公開期限 (RFC3339)
+}
+
+type UpdatePinRequest struct {
+	ContentText    *string `json:"content_text" binding:"omitempty,min=1"`
+	MediaID        *string `json:"media_id"`
+	PrivacySetting *string `json:"privacy_setting" binding:"omitempty,oneof=public friends private audience"`
+	AudienceID     *string `json:"audience_id"`
+	Status         *string `json:"status" binding:"omitempty,oneof=active archived"`
 }
 
 type GetPinsRequest struct {
@@ -30,7 +54,15 @@ type GetPinsRequest struct {
 	NeLng          float64 `form:"ne_lng" binding:"required"` // 北東 経度
 	SwLat          float64 `form:"sw_lat" binding:"required"` // 南西 緯度
 	SwLng          float64 `form:"sw_lng" binding:"required"` // 南西 経度
-	PrivacySetting string  `form:"privacy"`                   // 表示する公開設定（デフォルト: public）
+	PrivacySetting string  `form:"privacy"`                   // 表示フィルタ all / public / friends / mine（デフォルト: all）
+	Zoom           *int    `form:"zoom"`                      // 地図のズームレベル（指定時は低ズームでクラスタを返す）
+}
+
+type GetNearbyPinsRequest struct {
+	Lat          *float64 `form:"lat" binding:"required"`
+	Lng          *float64 `form:"lng" binding:"required"`
+	RadiusMeters float64  `form:"radius_m"` // 検索半径（デフォルト: 1000m）
+	Limit        int      `form:"limit"`
 }
 
 func (h *PinHandler) CreatePin(c *gin.Context) {
@@ -42,21 +74,46 @@ func (h *PinHandler) CreatePin(c *gin.Context) {
 		return
 	}
 
+	expiresAt := req.ExpiresAt
+	if req.ExpiresIn != nil {
+		if expiresAt != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "expires_in and expires_at cannot be used together"})
+			return
+		}
+		t := time.Now().Add(time.Duration(*req.ExpiresIn) * time.Second)
+		expiresAt = &t
+	}
+
 	pin, err := h.PinUsecase.PostNewPin(
 		userID,
-		req.Latitude,
-		req.Longitude,
+		usecase.LocationClaim{
+			Latitude:       req.Latitude,
+			Longitude:      req.Longitude,
+			AccuracyMeters: req.AccuracyMeters,
+			IsMocked:       req.IsMockLocation,
+			LocationToken:  req.LocationToken,
+			TravelMode:     req.TravelMode,
+		},
 		req.ContentText,
-		req.MediaURL,
+		req.MediaID,
 		req.PrivacySetting,
+		req.AudienceID,
+		expiresAt,
 	)
 
 	if err != nil {
+		var rejection *usecase.LocationRejection
 		switch {
-		case errors.Is(err, usecase.ErrInvalidPinCoordinates):
+		case errors.Is(err, usecase.ErrInvalidPinCoordinates),
+			errors.Is(err, usecase.ErrInvalidTravelMode),
+			errors.Is(err, usecase.ErrInvalidPinPrivacy),
+			errors.Is(err, usecase.ErrInvalidPinExpiry),
+			errors.Is(err, usecase.ErrInvalidPinMedia):
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		case errors.Is(err, usecase.ErrPinLocationDeviation):
+		case errors.Is(err, usecase.ErrEmailNotVerified):
 			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case errors.As(err, &rejection):
+			writeLocationRejection(c, rejection)
 		default:
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create pin"})
 		}
@@ -66,6 +123,20 @@ func (h *PinHandler) CreatePin(c *gin.Context) {
 	c.JSON(http.StatusCreated, gin.H{"message": "Pin created successfully", "pin": pin})
 }
 
+// writeLocationRejection は位置検証の拒否理由と、再投稿が可能になる時刻を返す
+func writeLocationRejection(c *gin.Context, rejection *usecase.LocationRejection) {
+	body := gin.H{"error": rejection.Error(), "reason": rejection.Reason}
+	if !rejection.RetryAt.IsZero() {
+		retryAfter := int(math.Ceil(time.Until(rejection.RetryAt).Seconds()))
+		if retryAfter < 1 {
+			retryAfter = 1
+		}
+		c.Header("Retry-After", strconv.Itoa(retryAfter))
+		body["retry_at"] = rejection.RetryAt.UTC().Format(time.RFC3339)
+	}
+	c.JSON(http.StatusForbidden, body)
+}
+
 func (h *PinHandler) GetPins(c *gin.Context) {
 	userID := middleware.GetUserIDFromContext(c)
 	var req GetPinsRequest
@@ -77,7 +148,33 @@ func (h *PinHandler) GetPins(c *gin.Context) {
 
 	privacy := req.PrivacySetting
 	if privacy == "" {
-		privacy = "public"
+		privacy = usecase.PinFilterAll
+	}
+
+	if req.Zoom != nil {
+		view, err := h.PinUsecase.GetMapView(
+			userID,
+			req.SwLat,
+			req.NeLat,
+			req.SwLng,
+			req.NeLng,
+			*req.Zoom,
+			privacy,
+		)
+		if err != nil {
+			switch {
+			case errors.Is(err, usecase.ErrInvalidZoomLevel),
+				errors.Is(err, usecase.ErrInvalidBoundingBox),
+				errors.Is(err, usecase.ErrInvalidPrivacyFilter):
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			default:
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve pins"})
+			}
+			return
+		}
+
+		c.JSON(http.StatusOK, view)
+		return
 	}
 
 	pins, err := h.PinUsecase.GetPinsForMap(
@@ -90,11 +187,266 @@ func (h *PinHandler) GetPins(c *gin.Context) {
 	)
 
 	if err != nil {
-		// ... エラー処理
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve pins"})
+		switch {
+		case errors.Is(err, usecase.ErrInvalidBoundingBox), errors.Is(err, usecase.ErrInvalidPrivacyFilter):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve pins"})
+		}
 		return
 	}
 
 	// 3. 成功レスポンスの返却
 	c.JSON(http.StatusOK, gin.H{"pins": pins})
 }
+
+func (h *PinHandler) GetNearbyPins(c *gin.Context) {
+	userID := middleware.GetUserIDFromContext(c)
+	var req GetNearbyPinsRequest
+
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters"})
+		return
+	}
+
+	pins, err := h.PinUsecase.GetNearbyPins(userID, *req.Lat, *req.Lng, req.RadiusMeters, req.Limit)
+	if err != nil {
+		switch {
+		case errors.Is(err, usecase.ErrInvalidPinCoordinates), errors.Is(err, usecase.ErrInvalidSearchRadius):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve pins"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"pins": pins})
+}
+
+// タイルのキャッシュ有効期間（閲覧者ごとに内容が異なるため private とする）
+const pinTileMaxAgeSeconds = 60
+
+// GetPinTile は /tiles/pins/{z}/{x}/{y}.mvt を返す
+func (h *PinHandler) GetPinTile(c *gin.Context) {
+	userID := middleware.GetUserIDFromContext(c)
+
+	z, errZ := strconv.Atoi(c.Param("z"))
+	x, errX := strconv.Atoi(c.Param("x"))
+	y, errY := strconv.Atoi(strings.TrimSuffix(c.Param("y"), ".mvt"))
+	if errZ != nil || errX != nil || errY != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tile coordinates"})
+		return
+	}
+
+	tile, err := h.PinUsecase.GetPinTile(userID, z, x, y)
+	if err != nil {
+		if errors.Is(err, usecase.ErrInvalidTileCoordinate) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate tile"})
+		return
+	}
+
+	// タイルの内容は閲覧者のフレンド関係に依存するため、内容そのもののハッシュをETagにする
+	sum := sha256.Sum256(tile)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", fmt.Sprintf("private, max-age=%d", pinTileMaxAgeSeconds))
+	c.Header("Vary", "Authorization")
+
+	if c.GetHeader("If-None-Match") == etag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	c.Data(http.StatusOK, "application/vnd.mapbox-vector-tile", tile)
+}
+
+// ExportPins は自分の足あとを GeoJSON / GPX / KML でダウンロードさせる
+func (h *PinHandler) ExportPins(c *gin.Context) {
+	userID := middleware.GetUserIDFromContext(c)
+	format := c.DefaultQuery("format", geofile.FormatGeoJSON)
+
+	if !geofile.IsSupported(format) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be one of geojson, gpx, kml"})
+		return
+	}
+
+	c.Header("Content-Type", geofile.ContentType(format))
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="ashiato-footprints.%s"`, format))
+	c.Status(http.StatusOK)
+
+	if err := h.PinUsecase.ExportPins(userID, format, c.Writer); err != nil {
+		// 書き出し開始前のエラーであれば通常のエラーレスポンスを返せる
+		if !c.Writer.Written() {
+			c.Writer.Header().Del("Content-Disposition")
+			c.Writer.Header().Set("Content-Type", "application/json; charset=utf-8")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export pins"})
+			return
+		}
+		// ストリーミング中のエラーは途中までしか返せないため、接続を打ち切る
+		log.Printf("pin export interrupted: %v", err)
+		c.Abort()
+	}
+}
+
+// アップロードできるインポートファイルの上限サイズ
+const maxImportFileBytes = 10 << 20 // 10MB
+
+type ImportPinsRequest struct {
+	Format         string `form:"format" binding:"omitempty,oneof=gpx geojson"`                     // 省略時はファイル名の拡張子から判定
+	PrivacySetting string `form:"privacy_setting" binding:"omitempty,oneof=public friends private"` // ファイルに指定がない地点の公開設定（デフォルト: friends）
+}
+
+// ImportPins はアップロードされた GPX / GeoJSON からピンを一括作成する
+func (h *PinHandler) ImportPins(c *gin.Context) {
+	userID := middleware.GetUserIDFromContext(c)
+	var req ImportPinsRequest
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportFileBytes)
+
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file is required (max 10MB)"})
+		return
+	}
+	defer file.Close()
+
+	format := req.Format
+	if format == "" {
+		format = geofile.DetectFormat(header.Filename)
+	}
+	privacy := req.PrivacySetting
+	if privacy == "" {
+		privacy = "friends"
+	}
+
+	result, err := h.PinUsecase.ImportPins(userID, format, file, privacy)
+	if err != nil {
+		switch {
+		case errors.Is(err, usecase.ErrUnsupportedFileFormat), errors.Is(err, usecase.ErrInvalidImportFile):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import pins"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+type GetTrailRequest struct {
+	From string `form:"from"` // RFC3339（デフォルト: to の1週間前）
+	To   string `form:"to"`   // RFC3339（デフォルト: 現在時刻）
+}
+
+// GetTrail はユーザーの足あとを時系列順の LineString として返す
+func (h *PinHandler) GetTrail(c *gin.Context) {
+	viewerID := middleware.GetUserIDFromContext(c)
+	ownerID := c.Param("user_id")
+	var req GetTrailRequest
+
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters"})
+		return
+	}
+
+	var from, to time.Time
+	var err error
+	if req.From != "" {
+		if from, err = time.Parse(time.RFC3339, req.From); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "from must be RFC3339"})
+			return
+		}
+	}
+	if req.To != "" {
+		if to, err = time.Parse(time.RFC3339, req.To); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "to must be RFC3339"})
+			return
+		}
+	}
+
+	trail, err := h.PinUsecase.GetTrail(viewerID, ownerID, from, to)
+	if err != nil {
+		if errors.Is(err, usecase.ErrInvalidTrailRequest) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve trail"})
+		return
+	}
+
+	c.JSON(http.StatusOK, trail)
+}
+
+func (h *PinHandler) GetPin(c *gin.Context) {
+	userID := middleware.GetUserIDFromContext(c)
+	pinID := c.Param("pin_id")
+
+	pin, err := h.PinUsecase.GetPin(userID, pinID)
+	if err != nil {
+		writePinError(c, err, "Failed to retrieve pin")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"pin": pin})
+}
+
+func (h *PinHandler) UpdatePin(c *gin.Context) {
+	userID := middleware.GetUserIDFromContext(c)
+	pinID := c.Param("pin_id")
+	var req UpdatePinRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+
+	pin, err := h.PinUsecase.UpdatePin(userID, pinID, usecase.PinUpdate{
+		ContentText:    req.ContentText,
+		MediaID:        req.MediaID,
+		PrivacySetting: req.PrivacySetting,
+		AudienceID:     req.AudienceID,
+		Status:         req.Status,
+	})
+	if err != nil {
+		writePinError(c, err, "Failed to update pin")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Pin updated successfully", "pin": pin})
+}
+
+func (h *PinHandler) DeletePin(c *gin.Context) {
+	userID := middleware.GetUserIDFromContext(c)
+	pinID := c.Param("pin_id")
+
+	if err := h.PinUsecase.DeletePin(userID, pinID); err != nil {
+		writePinError(c, err, "Failed to delete pin")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Pin deleted successfully"})
+}
+
+func writePinError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, usecase.ErrPinNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, usecase.ErrPinForbidden), errors.Is(err, usecase.ErrEmailNotVerified):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, usecase.ErrInvalidPinUpdate),
+		errors.Is(err, usecase.ErrInvalidPinPrivacy),
+		errors.Is(err, usecase.ErrInvalidPinMedia):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
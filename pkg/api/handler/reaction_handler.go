@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/k-kanke/ashiato-backend/pkg/api/middleware"
+	"github.com/k-kanke/ashiato-backend/pkg/usecase"
+)
+
+type ReactionHandler struct {
+	ReactionUsecase usecase.ReactionUsecase
+}
+
+func NewReactionHandler(uc usecase.ReactionUsecase) *ReactionHandler {
+	return &ReactionHandler{ReactionUsecase: uc}
+}
+
+func (h *ReactionHandler) AddReaction(c *gin.Context) {
+	userID := middleware.GetUserIDFromContext(c)
+	pinID := c.Param("pin_id")
+	emoji := c.Param("emoji")
+
+	if err := h.ReactionUsecase.AddReaction(userID, pinID, emoji); err != nil {
+		writeReactionError(c, err, "Failed to add reaction")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Reaction added successfully"})
+}
+
+func (h *ReactionHandler) RemoveReaction(c *gin.Context) {
+	userID := middleware.GetUserIDFromContext(c)
+	pinID := c.Param("pin_id")
+	emoji := c.Param("emoji")
+
+	if err := h.ReactionUsecase.RemoveReaction(userID, pinID, emoji); err != nil {
+		writeReactionError(c, err, "Failed to remove reaction")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Reaction removed successfully"})
+}
+
+func writeReactionError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, usecase.ErrInvalidReaction):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, usecase.ErrPinNotFound), errors.Is(err, usecase.ErrReactionNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/k-kanke/ashiato-backend/pkg/api/middleware"
+	"github.com/k-kanke/ashiato-backend/pkg/usecase"
+)
+
+type StreamHandler struct {
+	StreamUsecase usecase.StreamUsecase
+}
+
+func NewStreamHandler(uc usecase.StreamUsecase) *StreamHandler {
+	return &StreamHandler{StreamUsecase: uc}
+}
+
+// StreamRequest の矩形範囲は任意。指定がない場合は通知のみ配信する
+type StreamRequest struct {
+	NeLat *float64 `form:"ne_lat"` // 北東 緯度
+	NeLng *float64 `form:"ne_lng"` // 北東 経度
+	SwLat *float64 `form:"sw_lat"` // 南西 緯度
+	SwLng *float64 `form:"sw_lng"` // 南西 経度
+}
+
+// 接続維持のためのハートビート間隔
+const streamHeartbeatInterval = 25 * time.Second
+
+// Stream は Server-Sent Events で通知と新規ピンを配信する
+func (h *StreamHandler) Stream(c *gin.Context) {
+	claims := middleware.GetTokenClaimsFromContext(c)
+	var req StreamRequest
+
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters"})
+		return
+	}
+
+	var bounds *usecase.MapBounds
+	if req.NeLat != nil || req.NeLng != nil || req.SwLat != nil || req.SwLng != nil {
+		if req.NeLat == nil || req.NeLng == nil || req.SwLat == nil || req.SwLng == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "All of ne_lat, ne_lng, sw_lat and sw_lng are required"})
+			return
+		}
+		bounds = &usecase.MapBounds{
+			MinLat: *req.SwLat,
+			MaxLat: *req.NeLat,
+			MinLng: *req.SwLng,
+			MaxLng: *req.NeLng,
+		}
+	}
+
+	stream, err := h.StreamUsecase.OpenStream(claims, bounds)
+	if err != nil {
+		if errors.Is(err, usecase.ErrInvalidBoundingBox) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, usecase.ErrInvalidAccessToken) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to open stream"})
+		return
+	}
+	defer stream.Close()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // リバースプロキシでのバッファリングを無効化
+
+	heartbeat := time.NewTicker(streamHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	c.SSEvent("ready", gin.H{"user_id": claims.UserID})
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case event, ok := <-stream.Events:
+			if !ok {
+				return false
+			}
+			c.SSEvent(event.Type, event.Data)
+			return true
+		case <-heartbeat.C:
+			c.SSEvent("ping", gin.H{"time": time.Now().Unix()})
+			return true
+		}
+	})
+}
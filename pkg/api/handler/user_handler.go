@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -37,18 +38,14 @@ func (h *UserHandler) Register(c *gin.Context) {
 		return
 	}
 
-	token, err := h.UserUsecase.RegisterUser(req.Username, req.Email, req.Password)
+	tokens, err := h.UserUsecase.RegisterUser(req.Username, req.Email, req.Password, clientInfo(c))
 	if err != nil {
 		// 後でエラーの種類に応じてHTTPステータスコードを変えて返却するロジックを追加する
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Registration failed"})
 		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{
-		"message": "User registered successfully",
-		"token":   token,
-	})
-
+	c.JSON(http.StatusCreated, tokenResponse("User registered successfully", tokens))
 }
 
 func (h *UserHandler) Login(c *gin.Context) {
@@ -59,17 +56,25 @@ func (h *UserHandler) Login(c *gin.Context) {
 		return
 	}
 
-	token, err := h.UserUsecase.AuthenticateUser(req.Email, req.Password)
+	result, err := h.UserUsecase.AuthenticateUser(req.Email, req.Password, clientInfo(c))
 
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid email or password"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"message": "Login successful",
-		"token":   token,
-	})
+	// 二要素認証が有効な場合は POST /v1/auth/login/mfa でログインを完了する
+	if result.MFAChallenge != nil {
+		c.JSON(http.StatusOK, gin.H{
+			"message":      "Two-factor authentication required",
+			"mfa_required": true,
+			"mfa_token":    result.MFAChallenge.MFAToken,
+			"expires_in":   result.MFAChallenge.ExpiresIn,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, tokenResponse("Login successful", result.Tokens))
 }
 
 func (h *UserHandler) GetProfile(c *gin.Context) {
@@ -87,3 +92,149 @@ func (h *UserHandler) GetProfile(c *gin.Context) {
 
 	c.JSON(http.StatusOK, profile)
 }
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+func (h *UserHandler) VerifyEmail(c *gin.Context) {
+	var req VerifyEmailRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+
+	if err := h.UserUsecase.VerifyEmail(req.Token); err != nil {
+		if errors.Is(err, usecase.ErrInvalidActionToken) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify email"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Email verified successfully"})
+}
+
+func (h *UserHandler) ResendEmailVerification(c *gin.Context) {
+	userID := middleware.GetUserIDFromContext(c)
+
+	if err := h.UserUsecase.ResendEmailVerification(userID); err != nil {
+		if errors.Is(err, usecase.ErrEmailAlreadyVerified) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send verification email"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Verification email sent"})
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+func (h *UserHandler) ForgotPassword(c *gin.Context) {
+	var req ForgotPasswordRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+
+	if err := h.UserUsecase.RequestPasswordReset(req.Email); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send password reset email"})
+		return
+	}
+
+	// 登録済みかどうかに関わらず同じ応答を返す
+	c.JSON(http.StatusOK, gin.H{"message": "If the email is registered, a password reset link has been sent"})
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,min=8"`
+}
+
+func (h *UserHandler) ResetPassword(c *gin.Context) {
+	var req ResetPasswordRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+
+	if err := h.UserUsecase.ResetPassword(req.Token, req.Password); err != nil {
+		if errors.Is(err, usecase.ErrInvalidActionToken) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset password"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password reset successfully"})
+}
+
+type CreatePrivacyZoneRequest struct {
+	Name         string   `json:"name" binding:"required,max=50"`
+	Latitude     *float64 `json:"latitude" binding:"required"`
+	Longitude    *float64 `json:"longitude" binding:"required"`
+	RadiusMeters float64  `json:"radius_m" binding:"required"`
+	Mode         string   `json:"mode" binding:"required,oneof=hide fuzz"`
+}
+
+func (h *UserHandler) CreatePrivacyZone(c *gin.Context) {
+	userID := middleware.GetUserIDFromContext(c)
+	var req CreatePrivacyZoneRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+
+	zone, err := h.UserUsecase.CreatePrivacyZone(userID, req.Name, *req.Latitude, *req.Longitude, req.RadiusMeters, req.Mode)
+	if err != nil {
+		switch {
+		case errors.Is(err, usecase.ErrInvalidPrivacyZone):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, usecase.ErrPrivacyZoneLimitReached):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create privacy zone"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Privacy zone created successfully", "zone": zone})
+}
+
+func (h *UserHandler) GetPrivacyZones(c *gin.Context) {
+	userID := middleware.GetUserIDFromContext(c)
+
+	zones, err := h.UserUsecase.GetPrivacyZones(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve privacy zones"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"zones": zones})
+}
+
+func (h *UserHandler) DeletePrivacyZone(c *gin.Context) {
+	userID := middleware.GetUserIDFromContext(c)
+	zoneID := c.Param("zone_id")
+
+	if err := h.UserUsecase.DeletePrivacyZone(userID, zoneID); err != nil {
+		if errors.Is(err, usecase.ErrPrivacyZoneNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete privacy zone"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Privacy zone deleted successfully"})
+}
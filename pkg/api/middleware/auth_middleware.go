@@ -2,18 +2,18 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
-	"os"
 
 	"github.com/gin-gonic/gin"
 	"github.com/k-kanke/ashiato-backend/pkg/shared"
+	"github.com/k-kanke/ashiato-backend/pkg/usecase"
 )
 
 // AuthMiddleware はJWT認証を検証するミドルウェア
-func AuthMiddleware() gin.HandlerFunc {
+func AuthMiddleware(authUc usecase.AuthUsecase) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
-		secret := os.Getenv("JWT_SECRET")
 
 		// 1. ヘッダーからトークンを抽出
 		tokenString, err := shared.ExtractTokenFromHeader(authHeader)
@@ -23,16 +23,24 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		// 2. トークンを検証し、UserIDを取得
-		userID, err := shared.ParseToken(tokenString, secret)
+		// 2. トークンを検証し（失効したセッションのトークンも拒否する）、UserIDを取得
+		claims, err := authUc.AuthenticateAccessToken(tokenString)
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+			switch {
+			case errors.Is(err, usecase.ErrSessionRevoked):
+				c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			case errors.Is(err, usecase.ErrInvalidAccessToken):
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+			default:
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify token"})
+			}
 			c.Abort()
 			return
 		}
 
-		// 3. 検証成功: UserIDをコンテキストに格納し、次のハンドラーへ
-		c.Set("user_id", userID)
+		// 3. 検証成功: UserIDとクレームをコンテキストに格納し、次のハンドラーへ
+		c.Set("user_id", claims.UserID)
+		c.Set("token_claims", claims)
 		c.Next()
 	}
 }
@@ -47,3 +55,13 @@ func GetUserIDFromContext(c *gin.Context) string {
 	}
 	return ""
 }
+
+// GetTokenClaimsFromContext はAuthMiddlewareで検証したアクセストークンのクレームを取得する
+func GetTokenClaimsFromContext(c *gin.Context) *shared.Claims {
+	if claims, exists := c.Get("token_claims"); exists {
+		if parsed, ok := claims.(*shared.Claims); ok {
+			return parsed
+		}
+	}
+	return nil
+}
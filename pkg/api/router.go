@@ -6,25 +6,36 @@ import (
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	"github.com/k-kanke/ashiato-backend/pkg/api/handler"
-	"github.com/k-kanke/ashiato-backend/pkg/api/middleware"
 )
 
 func SetupRouter(
+	authMiddleware gin.HandlerFunc,
+	authHandler *handler.AuthHandler,
 	userHandler *handler.UserHandler,
 	pinHandler *handler.PinHandler,
 	friendHandler *handler.FriendHandler,
+	commentHandler *handler.CommentHandler,
+	notificationHandler *handler.NotificationHandler,
+	streamHandler *handler.StreamHandler,
+	audienceHandler *handler.AudienceHandler,
+	reactionHandler *handler.ReactionHandler,
+	mediaHandler *handler.MediaHandler,
+	mfaHandler *handler.MFAHandler,
 ) *gin.Engine {
 	router := gin.Default()
 
 	router.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"http://localhost:3001"},
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "If-None-Match"},
+		ExposeHeaders:    []string{"Content-Length", "ETag", "Retry-After"},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	}))
 
+	// トークン検証用の公開鍵 (JWKS)
+	router.GET("/.well-known/jwks.json", authHandler.GetJWKS)
+
 	v1 := router.Group("/v1")
 	{
 		// 認証エンドポイント
@@ -32,26 +43,102 @@ func SetupRouter(
 		{
 			auth.POST("/register", userHandler.Register)
 			auth.POST("/login", userHandler.Login)
+			auth.POST("/login/mfa", mfaHandler.CompleteLogin)
+			auth.POST("/refresh", authHandler.RefreshToken)
+			auth.POST("/verify-email", userHandler.VerifyEmail)
+			auth.POST("/forgot-password", userHandler.ForgotPassword)
+			auth.POST("/reset-password", userHandler.ResetPassword)
+			// 現在のセッションを失効させるため認証が必要
+			auth.POST("/logout", authMiddleware, authHandler.Logout)
 		}
 	}
 
 	protected := v1.Group("/")
-	protected.Use(middleware.AuthMiddleware())
+	protected.Use(authMiddleware)
 	{
 		// プロフィール情報取得
 		protected.GET("/me", userHandler.GetProfile)
+		protected.POST("/me/email-verification", userHandler.ResendEmailVerification)
+		protected.GET("/me/export", pinHandler.ExportPins)
+		protected.POST("/me/import", pinHandler.ImportPins)
+		protected.GET("/me/privacy-zones", userHandler.GetPrivacyZones)
+		protected.POST("/me/privacy-zones", userHandler.CreatePrivacyZone)
+		protected.DELETE("/me/privacy-zones/:zone_id", userHandler.DeletePrivacyZone)
+		protected.GET("/me/sessions", authHandler.GetSessions)
+		protected.DELETE("/me/sessions", authHandler.RevokeAllSessions)
+		protected.DELETE("/me/sessions/:session_id", authHandler.RevokeSession)
+
+		// 二要素認証 (TOTP)
+		protected.POST("/me/mfa/totp", mfaHandler.BeginTOTPEnrollment)
+		protected.POST("/me/mfa/totp/confirm", mfaHandler.ConfirmTOTPEnrollment)
+		protected.POST("/me/mfa/recovery-codes", mfaHandler.RegenerateRecoveryCodes)
+		protected.DELETE("/me/mfa", mfaHandler.DisableMFA)
+
+		// メディアのアップロード・配信（配信はアップロードした本人と、添付先のピンを閲覧できるユーザーのみ）
+		protected.POST("/media", mediaHandler.UploadMedia)
+		protected.GET("/media/:media_id", mediaHandler.GetMedia)
+		protected.GET("/media/:media_id/thumbnail", mediaHandler.GetMediaThumbnail)
 
 		// ピン
 		protected.POST("/pins", pinHandler.CreatePin)
 		protected.GET("/pins", pinHandler.GetPins)
+		protected.GET("/pins/nearby", pinHandler.GetNearbyPins)
+		protected.GET("/pins/:pin_id", pinHandler.GetPin)
+		protected.PATCH("/pins/:pin_id", pinHandler.UpdatePin)
+		protected.DELETE("/pins/:pin_id", pinHandler.DeletePin)
+
+		// ユーザーの足あとの軌跡
+		protected.GET("/users/:user_id/trail", pinHandler.GetTrail)
+
+		// ベクタータイル (/tiles/pins/{z}/{x}/{y}.mvt)
+		protected.GET("/tiles/pins/:z/:x/:y", pinHandler.GetPinTile)
+
+		// コメント
+		protected.POST("/pins/:pin_id/comments", commentHandler.CreateComment)
+		protected.GET("/pins/:pin_id/comments", commentHandler.GetComments)
+		protected.DELETE("/pins/:pin_id/comments/:comment_id", commentHandler.DeleteComment)
+
+		// リアクション
+		protected.PUT("/pins/:pin_id/reactions/:emoji", reactionHandler.AddReaction)
+		protected.DELETE("/pins/:pin_id/reactions/:emoji", reactionHandler.RemoveReaction)
 
 		// フレンド関連
 		friend := protected.Group("/friends")
 		{
 			friend.POST("/:user_id/request", friendHandler.RequestFriendship)
 			friend.POST("/:user_id/accept", friendHandler.AcceptFriendship)
+			friend.POST("/:user_id/reject", friendHandler.RejectFriendship)
+			friend.POST("/:user_id/cancel", friendHandler.CancelFriendship)
+			friend.DELETE("/:user_id", friendHandler.Unfriend)
+			friend.POST("/:user_id/block", friendHandler.BlockUser)
+			friend.DELETE("/:user_id/block", friendHandler.UnblockUser)
 			friend.GET("", friendHandler.GetFriendsList)
+			friend.GET("/requests/incoming", friendHandler.GetIncomingRequests)
+			friend.GET("/requests/outgoing", friendHandler.GetOutgoingRequests)
+			friend.GET("/suggestions", friendHandler.GetFriendSuggestions)
 		}
+
+		// オーディエンスリスト（ピンの公開先）
+		audience := protected.Group("/audiences")
+		{
+			audience.POST("", audienceHandler.CreateAudience)
+			audience.GET("", audienceHandler.GetAudiences)
+			audience.DELETE("/:audience_id", audienceHandler.DeleteAudience)
+			audience.PUT("/:audience_id/members/:user_id", audienceHandler.AddMember)
+			audience.DELETE("/:audience_id/members/:user_id", audienceHandler.RemoveMember)
+		}
+
+		// 通知
+		notification := protected.Group("/notifications")
+		{
+			notification.GET("", notificationHandler.GetNotifications)
+			notification.GET("/unread-count", notificationHandler.GetUnreadCount)
+			notification.POST("/read-all", notificationHandler.MarkAllAsRead)
+			notification.POST("/:notification_id/read", notificationHandler.MarkAsRead)
+		}
+
+		// リアルタイム配信 (Server-Sent Events)
+		protected.GET("/stream", streamHandler.Stream)
 	}
 
 	return router
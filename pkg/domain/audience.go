@@ -0,0 +1,12 @@
+package domain
+
+import "time"
+
+// Audience はユーザーが管理する公開先リスト（例: 親しい友達）
+type Audience struct {
+	AudienceID string    `json:"audience_id"`
+	UserID     string    `json:"user_id"` // リストの所有者
+	Name       string    `json:"name"`
+	MemberIDs  []string  `json:"member_ids"`
+	CreatedAt  time.Time `json:"created_at"`
+}
@@ -0,0 +1,57 @@
+package domain
+
+import "time"
+
+// Session はログインした端末ごとのセッション
+// 同じログインから発行されたリフレッシュトークン・アクセストークンは全て同じセッションに属する
+type Session struct {
+	SessionID  string     `json:"session_id"`
+	UserID     string     `json:"-"`
+	UserAgent  string     `json:"user_agent"`
+	IPAddress  string     `json:"ip_address"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastSeenAt time.Time  `json:"last_seen_at"`
+	ExpiresAt  time.Time  `json:"expires_at"` // 最新のリフレッシュトークンの有効期限
+	RevokedAt  *time.Time `json:"-"`
+	Current    bool       `json:"current"` // リクエストしたトークンのセッションかどうか
+}
+
+// RefreshToken はアクセストークンを再発行するためのトークン
+// ローテーションのたびに新しい行を作り、同じセッションのトークンは SessionID を共有する
+type RefreshToken struct {
+	TokenID   string
+	UserID    string
+	SessionID string
+	TokenHash string // トークン本体は保存せず SHA-256 のみを持つ
+	ExpiresAt time.Time
+	UsedAt    *time.Time // ローテーション済みの場合に設定される
+	RevokedAt *time.Time
+	CreatedAt time.Time
+}
+
+// 一度だけ使えるトークンの用途
+const (
+	ActionTokenEmailVerification = "email_verification"
+	ActionTokenPasswordReset     = "password_reset"
+	ActionTokenMFAChallenge      = "mfa_challenge" // パスワード認証後、二要素認証の完了を待つログイン
+)
+
+// ActionToken はメールアドレス確認・パスワード再設定・二要素認証待ちのログインに使う使い捨てトークン
+type ActionToken struct {
+	TokenHash string // トークン本体は保存せず SHA-256 のみを持つ
+	UserID    string
+	Purpose   string
+	Attempts  int // 検証に失敗した回数
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+// UserMFA はユーザーの TOTP 二要素認証の設定
+type UserMFA struct {
+	UserID          string
+	SecretEncrypted string     // 暗号化した TOTP の秘密鍵
+	EnabledAt       *time.Time // 登録の確認が済むまでは nil
+	LastUsedStep    int64      // 最後に使われた TOTP のステップ（同じコードの再利用を防ぐ）
+	CreatedAt       time.Time
+}
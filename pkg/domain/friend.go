@@ -2,6 +2,15 @@ package domain
 
 import "time"
 
+// フレンド関係の状態 (friends.status)
+const (
+	FriendshipStatusPending   = "pending"
+	FriendshipStatusAccepted  = "accepted"
+	FriendshipStatusRejected  = "rejected"
+	FriendshipStatusCancelled = "cancelled"
+	FriendshipStatusBlocked   = "blocked"
+)
+
 type Friendship struct {
 	UserAID      string    `json:"user_a_id"`
 	UserBID      string    `json:"user_b_id"`
@@ -10,3 +19,31 @@ type Friendship struct {
 	CreatedAt    time.Time `json:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at"`
 }
+
+// FriendSummary は一覧表示用の相手ユーザーの概要
+type FriendSummary struct {
+	UserID          string    `json:"user_id"`
+	Username        string    `json:"username"`
+	ProfileImageURL string    `json:"profile_image_url"`
+	Since           time.Time `json:"since"` // フレンドになった日時、または申請日時
+}
+
+// FriendSuggestion はフレンド候補とその根拠
+type FriendSuggestion struct {
+	UserID            string   `json:"user_id"`
+	Username          string   `json:"username"`
+	ProfileImageURL   string   `json:"profile_image_url"`
+	MutualFriendCount int      `json:"mutual_friend_count"`
+	NearbyPinCount    int      `json:"nearby_pin_count"` // 自分の最近のピンの近くにある相手の公開ピン数
+	Reasons           []string `json:"reasons"`
+}
+
+// SuggestionScoring はフレンド候補の並び順を決める重み
+// スコアは 共通のフレンド数 * MutualFriendWeight + min(近くの公開ピン数, NearbyPinCountCap) * NearbyPinWeight
+type SuggestionScoring struct {
+	RecentPinCount     int     // 比較対象にする自分の最近のピン数
+	RadiusMeters       float64 // 「近く」とみなす距離
+	MutualFriendWeight float64
+	NearbyPinWeight    float64
+	NearbyPinCountCap  int // 投稿数の多いユーザーが上位を独占しないよう上限を設ける
+}
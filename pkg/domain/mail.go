@@ -0,0 +1,8 @@
+package domain
+
+// MailMessage は送信するテキストメール
+type MailMessage struct {
+	To      string
+	Subject string
+	Body    string
+}
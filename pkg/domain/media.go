@@ -0,0 +1,18 @@
+package domain
+
+import "time"
+
+// Media はアップロードされた画像（EXIFを除去済み）とそのサムネイル
+type Media struct {
+	MediaID      string    `json:"media_id"`
+	UserID       string    `json:"user_id"`
+	ContentType  string    `json:"content_type"`
+	Width        int       `json:"width"`
+	Height       int       `json:"height"`
+	SizeBytes    int64     `json:"size_bytes"`
+	StorageKey   string    `json:"-"`
+	ThumbnailKey string    `json:"-"`
+	URL          string    `json:"url"`
+	ThumbnailURL string    `json:"thumbnail_url"`
+	CreatedAt    time.Time `json:"created_at"`
+}
@@ -2,6 +2,15 @@ package domain
 
 import "time"
 
+// 通知の種類 (notifications.type)
+const (
+	NotificationTypeFriendRequest  = "friend_request"
+	NotificationTypeFriendAccepted = "friend_accepted"
+	NotificationTypeComment        = "comment"
+	NotificationTypeFriendNewPin   = "friend_new_pin"
+	NotificationTypeReaction       = "reaction"
+)
+
 type Notification struct {
 	NotificationID  string    `json:"notification_id"`
 	RecipientUserID string    `json:"recipient_user_id"`
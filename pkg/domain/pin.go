@@ -2,14 +2,48 @@ package domain
 
 import "time"
 
+// ピンの状態 (pins.status)
+const (
+	PinStatusActive   = "active"   // 地図に表示される通常の状態
+	PinStatusArchived = "archived" // 所有者のみ閲覧できる状態
+	PinStatusDeleted  = "deleted"  // 論理削除済み。誰からも参照できない
+	PinStatusExpired  = "expired"  // 公開期限切れ。所有者のみ閲覧できる
+)
+
+// ピンの公開範囲 (pins.privacy_setting)
+const (
+	PinPrivacyPublic   = "public"   // 全てのユーザーに表示
+	PinPrivacyFriends  = "friends"  // acceptedなフレンドに表示
+	PinPrivacyPrivate  = "private"  // 投稿者本人のみ
+	PinPrivacyAudience = "audience" // 投稿者が管理するオーディエンスリストのメンバー（フレンドのみ）に表示
+)
+
 type Pin struct {
-	PinID          string    `json:"pin_id"`
-	UserID         string    `json:"user_id"`
-	Latitude       float64   `json:"latitude"`
-	Longitude      float64   `json:"longitude"`
-	ContentText    string    `json:"content_text"`
-	MediaURL       string    `json:"media_url"`
-	PrivacySetting string    `json:"privacy_setting"`
-	Status         string    `json:"status"`
-	CreatedAt      time.Time `json:"created_at"`
+	PinID          string          `json:"pin_id"`
+	UserID         string          `json:"user_id"`
+	Latitude       float64         `json:"latitude"`
+	Longitude      float64         `json:"longitude"`
+	ContentText    string          `json:"content_text"`
+	MediaURL       string          `json:"media_url"`
+	PrivacySetting string          `json:"privacy_setting"`
+	AudienceID     string          `json:"audience_id,omitempty"` // privacy_setting が audience の場合の公開先
+	Status         string          `json:"status"`
+	IsImported     bool            `json:"is_imported"`          // GPSログなどから一括インポートされたピン
+	ExpiresAt      *time.Time      `json:"expires_at,omitempty"` // この時刻を過ぎると地図・検索に表示しない（nil は無期限）
+	Reactions      []ReactionCount `json:"reactions,omitempty"`  // 地図・単一取得時のみ集計して付与
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+// NearbyPin は検索地点からの距離付きのピン
+type NearbyPin struct {
+	Pin
+	DistanceMeters float64 `json:"distance_m"`
+}
+
+// PinCluster は低ズーム時に近接するピンをまとめたもの
+type PinCluster struct {
+	Latitude    float64 `json:"latitude"`  // クラスタ内のピンの重心
+	Longitude   float64 `json:"longitude"` // クラスタ内のピンの重心
+	Count       int     `json:"count"`
+	SamplePinID string  `json:"sample_pin_id"` // クラスタ内で最も新しいピン
 }
@@ -0,0 +1,19 @@
+package domain
+
+import "time"
+
+// Reaction はピンに対する絵文字リアクション（ユーザー・絵文字ごとに1件）
+type Reaction struct {
+	PinID     string    `json:"pin_id"`
+	UserID    string    `json:"user_id"`
+	Emoji     string    `json:"emoji"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ReactionCount はピンに付いた絵文字ごとのリアクション数
+type ReactionCount struct {
+	PinID       string `json:"-"`
+	Emoji       string `json:"emoji"`
+	Count       int    `json:"count"`
+	ReactedByMe bool   `json:"reacted_by_me"` // 閲覧者自身がこの絵文字でリアクションしているか
+}
@@ -0,0 +1,14 @@
+package domain
+
+// リアルタイム配信イベントの種類
+const (
+	StreamEventNotification = "notification"
+	StreamEventPin          = "pin"
+	StreamEventSessionEnded = "session_ended" // セッションの失効・トークンの期限切れでストリームを閉じる直前に送る
+)
+
+// StreamEvent はストリーミングでクライアントに配信するイベント
+type StreamEvent struct {
+	Type string      `json:"type"`
+	Data interface{} `json:"data"`
+}
@@ -3,15 +3,16 @@ package domain
 import "time"
 
 type User struct {
-	UserID          string    `json:"user_id"`
-	Username        string    `json:"username"`
-	Email           string    `json:"email"`
-	PasswordHash    string    `json:"-"`
-	ProfileImageURL string    `json:"profile_image_url"`
-	Bio             string    `json:"bio"`
-	IsBanned        bool      `json:"-"`
-	CreatedAt       time.Time `json:"created_at"`
-	UpdatedAt       time.Time `json:"updated_at"`
+	UserID          string     `json:"user_id"`
+	Username        string     `json:"username"`
+	Email           string     `json:"email"`
+	PasswordHash    string     `json:"-"`
+	ProfileImageURL string     `json:"profile_image_url"`
+	Bio             string     `json:"bio"`
+	IsBanned        bool       `json:"-"`
+	EmailVerifiedAt *time.Time `json:"-"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
 }
 
 type UserSettings struct {
@@ -20,4 +21,23 @@ type UserSettings struct {
 	FriendNewPin          bool   `json:"friend_new_pin"`
 	FriendRequestReceived bool   `json:"friend_request_received"`
 	FriendRequestAccepted bool   `json:"friend_request_accepted"`
+	ReactionOnMyPin       bool   `json:"reaction_on_my_pin"`
+}
+
+// プライバシーゾーン内のピンを他のユーザーにどう見せるか
+const (
+	PrivacyZoneModeHide = "hide" // 他のユーザーには表示しない
+	PrivacyZoneModeFuzz = "fuzz" // 座標をグリッドに寄せ、ずらして表示する
+)
+
+// PrivacyZone は自宅や職場など、ピンの正確な位置を隠したい円形の範囲
+type PrivacyZone struct {
+	ZoneID       string    `json:"zone_id"`
+	UserID       string    `json:"user_id"`
+	Name         string    `json:"name"`
+	Latitude     float64   `json:"latitude"`
+	Longitude    float64   `json:"longitude"`
+	RadiusMeters float64   `json:"radius_m"`
+	Mode         string    `json:"mode"`
+	CreatedAt    time.Time `json:"created_at"`
 }
@@ -0,0 +1,164 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/k-kanke/ashiato-backend/pkg/domain"
+	"github.com/k-kanke/ashiato-backend/pkg/repository"
+	"github.com/lib/pq"
+)
+
+type postgresAudienceRepository struct {
+	client *DBClient
+}
+
+func NewAudienceRepository(client *DBClient) repository.AudienceRepository {
+	return &postgresAudienceRepository{client: client}
+}
+
+func (r *postgresAudienceRepository) CreateAudience(audience *domain.Audience) error {
+	tx, err := r.client.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() // Commit 後の Rollback は何もしない
+
+	const audienceQuery = `
+        INSERT INTO audiences (audience_id, user_id, name, created_at)
+        VALUES ($1, $2, $3, $4)
+    `
+	if _, err := tx.Exec(audienceQuery, audience.AudienceID, audience.UserID, audience.Name, audience.CreatedAt); err != nil {
+		return fmt.Errorf("failed to insert audience: %w", err)
+	}
+
+	const memberQuery = `
+        INSERT INTO audience_members (audience_id, member_user_id)
+        SELECT $1, UNNEST($2::uuid[])
+        ON CONFLICT DO NOTHING
+    `
+	if _, err := tx.Exec(memberQuery, audience.AudienceID, pq.Array(audience.MemberIDs)); err != nil {
+		return fmt.Errorf("failed to insert audience members: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit audience: %w", err)
+	}
+	return nil
+}
+
+// audienceColumns はメンバーIDを配列にまとめて読み出す（scanAudience と順序を合わせる）
+const audienceColumns = `
+            a.audience_id, a.user_id, a.name, a.created_at,
+            COALESCE(
+                ARRAY_AGG(m.member_user_id::text ORDER BY m.member_user_id) FILTER (WHERE m.member_user_id IS NOT NULL),
+                '{}'
+            ) AS member_ids`
+
+func scanAudience(row rowScanner, audience *domain.Audience) error {
+	var memberIDs pq.StringArray
+	if err := row.Scan(
+		&audience.AudienceID,
+		&audience.UserID,
+		&audience.Name,
+		&audience.CreatedAt,
+		&memberIDs,
+	); err != nil {
+		return err
+	}
+	audience.MemberIDs = []string(memberIDs)
+	return nil
+}
+
+func (r *postgresAudienceRepository) FindAudienceByID(audienceID string) (*domain.Audience, error) {
+	const query = `
+        SELECT ` + audienceColumns + `
+        FROM audiences a
+        LEFT JOIN audience_members m ON m.audience_id = a.audience_id
+        WHERE a.audience_id = $1
+        GROUP BY a.audience_id
+    `
+
+	var audience domain.Audience
+	if err := scanAudience(r.client.DB.QueryRow(query, audienceID), &audience); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find audience: %w", err)
+	}
+
+	return &audience, nil
+}
+
+func (r *postgresAudienceRepository) GetAudiencesByUser(userID string) ([]domain.Audience, error) {
+	const query = `
+        SELECT ` + audienceColumns + `
+        FROM audiences a
+        LEFT JOIN audience_members m ON m.audience_id = a.audience_id
+        WHERE a.user_id = $1
+        GROUP BY a.audience_id
+        ORDER BY a.created_at ASC
+    `
+
+	rows, err := r.client.DB.Query(query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query audiences: %w", err)
+	}
+	defer rows.Close()
+
+	audiences := make([]domain.Audience, 0)
+	for rows.Next() {
+		var audience domain.Audience
+		if err := scanAudience(rows, &audience); err != nil {
+			return nil, fmt.Errorf("failed to scan audience: %w", err)
+		}
+		audiences = append(audiences, audience)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return audiences, nil
+}
+
+func (r *postgresAudienceRepository) DeleteAudience(userID, audienceID string) (bool, error) {
+	// メンバーは ON DELETE CASCADE で削除され、このリストに公開していたピンは投稿者のみが閲覧できる状態になる
+	result, err := r.client.DB.Exec(`DELETE FROM audiences WHERE audience_id = $1 AND user_id = $2`, audienceID, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete audience: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rowsAffected > 0, nil
+}
+
+func (r *postgresAudienceRepository) AddAudienceMember(audienceID, memberID string) error {
+	const query = `
+        INSERT INTO audience_members (audience_id, member_user_id)
+        VALUES ($1, $2)
+        ON CONFLICT DO NOTHING
+    `
+	if _, err := r.client.DB.Exec(query, audienceID, memberID); err != nil {
+		return fmt.Errorf("failed to insert audience member: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresAudienceRepository) RemoveAudienceMember(audienceID, memberID string) (bool, error) {
+	const query = `DELETE FROM audience_members WHERE audience_id = $1 AND member_user_id = $2`
+
+	result, err := r.client.DB.Exec(query, audienceID, memberID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete audience member: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rowsAffected > 0, nil
+}
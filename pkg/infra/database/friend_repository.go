@@ -8,6 +8,7 @@ import (
 
 	"github.com/k-kanke/ashiato-backend/pkg/domain"
 	"github.com/k-kanke/ashiato-backend/pkg/repository"
+	"github.com/k-kanke/ashiato-backend/pkg/shared"
 )
 
 type postgresFriendRepository struct {
@@ -88,39 +89,245 @@ func (r *postgresFriendRepository) UpdateFriendshipStatus(userA, userB, newStatu
 	return nil
 }
 
-func (r *postgresFriendRepository) GetFriendsList(userID string) ([]string, error) {
+func (r *postgresFriendRepository) SaveFriendship(userA, userB, status, actionUserID string) error {
+	// ユーザーIDを正規化 (userAID < userBID)
+	id1, id2 := userA, userB
+	if userA > userB {
+		id1, id2 = userB, userA
+	}
+
+	now := time.Now()
 	query := `
-        SELECT 
-            CASE
-                WHEN user_a_id = $1 THEN user_b_id
-                ELSE user_a_id
-            END AS friend_id
-        FROM friends
+        INSERT INTO friends 
+            (user_a_id, user_b_id, status, action_user_id, created_at, updated_at)
+        VALUES ($1, $2, $3, $4, $5, $5)
+        ON CONFLICT (user_a_id, user_b_id) DO UPDATE
+        SET 
+            status = EXCLUDED.status,
+            action_user_id = EXCLUDED.action_user_id,
+            updated_at = EXCLUDED.updated_at
+    `
+
+	if _, err := r.client.DB.Exec(query, id1, id2, status, actionUserID, now); err != nil {
+		return fmt.Errorf("failed to save friendship: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresFriendRepository) DeleteFriendship(userA, userB string) error {
+	// ユーザーIDを正規化 (userAID < userBID)
+	id1, id2 := userA, userB
+	if userA > userB {
+		id1, id2 = userB, userA
+	}
+
+	result, err := r.client.DB.Exec(`DELETE FROM friends WHERE user_a_id = $1 AND user_b_id = $2`, id1, id2)
+	if err != nil {
+		return fmt.Errorf("failed to delete friendship: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return errors.New("friendship record not found or already deleted")
+	}
+
+	return nil
+}
+
+func (r *postgresFriendRepository) GetFriendsList(
+	userID string,
+	cursor *shared.Cursor,
+	limit int,
+) ([]domain.FriendSummary, error) {
+	// ステータスが 'accepted' であること
+	return r.listFriendSummaries(userID, `f.status = 'accepted'`, cursor, limit)
+}
+
+func (r *postgresFriendRepository) GetIncomingRequests(
+	userID string,
+	cursor *shared.Cursor,
+	limit int,
+) ([]domain.FriendSummary, error) {
+	// 申請中で、最後に操作したのが相手（= 相手からの申請）であること
+	return r.listFriendSummaries(userID, `f.status = 'pending' AND f.action_user_id <> $1`, cursor, limit)
+}
+
+func (r *postgresFriendRepository) GetOutgoingRequests(
+	userID string,
+	cursor *shared.Cursor,
+	limit int,
+) ([]domain.FriendSummary, error) {
+	// 申請中で、最後に操作したのが自分（= 自分からの申請）であること
+	return r.listFriendSummaries(userID, `f.status = 'pending' AND f.action_user_id = $1`, cursor, limit)
+}
+
+// listFriendSummaries は条件に一致する関係の相手ユーザーを updated_at の新しい順に取得する
+func (r *postgresFriendRepository) listFriendSummaries(
+	userID string,
+	condition string,
+	cursor *shared.Cursor,
+	limit int,
+) ([]domain.FriendSummary, error) {
+	query := `
+        SELECT u.user_id, u.username, u.profile_image_url, f.updated_at
+        FROM friends f
+        JOIN users u 
+            ON u.user_id = CASE
+                WHEN f.user_a_id = $1 THEN f.user_b_id
+                ELSE f.user_a_id
+            END
         WHERE 
             -- user_a_id または user_b_id が自身のIDであり、
-            (user_a_id = $1 OR user_b_id = $1)
-            -- ステータスが 'accepted' であること
-            AND status = 'accepted'
-    `
+            (f.user_a_id = $1 OR f.user_b_id = $1)
+            AND ` + condition
+	args := []interface{}{userID}
+	if cursor != nil {
+		query += ` AND (f.updated_at, u.user_id) < ($2, $3)`
+		args = append(args, cursor.CreatedAt, cursor.ID)
+	}
+	query += fmt.Sprintf(` ORDER BY f.updated_at DESC, u.user_id DESC LIMIT %d`, limit)
 
-	rows, err := r.client.DB.Query(query, userID)
+	rows, err := r.client.DB.Query(query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query friends list: %w", err)
 	}
 	defer rows.Close()
 
-	var friendIDs []string
+	summaries := make([]domain.FriendSummary, 0)
 	for rows.Next() {
-		var friendID string
-		if err := rows.Scan(&friendID); err != nil {
-			return nil, fmt.Errorf("failed to scan friend ID: %w", err)
+		var summary domain.FriendSummary
+		var profileImageURL sql.NullString
+		if err := rows.Scan(&summary.UserID, &summary.Username, &profileImageURL, &summary.Since); err != nil {
+			return nil, fmt.Errorf("failed to scan friend summary: %w", err)
+		}
+		if profileImageURL.Valid {
+			summary.ProfileImageURL = profileImageURL.String
+		}
+		summaries = append(summaries, summary)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return summaries, nil
+}
+
+func (r *postgresFriendRepository) GetSuggestionCandidates(
+	userID string,
+	scoring domain.SuggestionScoring,
+	limit int,
+) ([]domain.FriendSuggestion, error) {
+	const query = `
+        WITH my_friends AS (
+            SELECT CASE WHEN user_a_id = $1 THEN user_b_id ELSE user_a_id END AS friend_id
+            FROM friends
+            WHERE (user_a_id = $1 OR user_b_id = $1) AND status = 'accepted'
+        ),
+        -- 状態に関わらず既に関係があるユーザー（フレンド・申請中・拒否・ブロックなど）
+        related AS (
+            SELECT CASE WHEN user_a_id = $1 THEN user_b_id ELSE user_a_id END AS related_id
+            FROM friends
+            WHERE user_a_id = $1 OR user_b_id = $1
+        ),
+        -- フレンドのフレンドを共通のフレンド数で集計する
+        mutual AS (
+            SELECT 
+                CASE WHEN f.user_a_id = mf.friend_id THEN f.user_b_id ELSE f.user_a_id END AS candidate_id,
+                COUNT(*) AS mutual_count
+            FROM friends f
+            JOIN my_friends mf ON (f.user_a_id = mf.friend_id OR f.user_b_id = mf.friend_id)
+            WHERE f.status = 'accepted'
+            GROUP BY 1
+        ),
+        my_recent_pins AS (
+            SELECT location
+            FROM pins
+            WHERE user_id = $1 AND status = 'active'
+            ORDER BY created_at DESC
+            LIMIT $2
+        ),
+        -- 自分の最近のピンから半径内にある他ユーザーの公開ピンを集計する
+        nearby AS (
+            SELECT p.user_id AS candidate_id, COUNT(DISTINCT p.pin_id) AS nearby_count
+            FROM pins p
+            JOIN my_recent_pins r 
+                ON ST_DWithin(p.location::geography, r.location::geography, $3)
+            WHERE p.privacy_setting = 'public' AND ` + pinActiveCondition + ` AND p.user_id <> $1
+                -- プライバシーゾーンチェック: 非表示ゾーン内のピンは近くにいる根拠に使わない
+                AND NOT EXISTS (
+                    SELECT 1 FROM privacy_zones z
+                    WHERE z.user_id = p.user_id
+                        AND z.mode = 'hide'
+                        AND ST_DWithin(z.center::geography, p.location::geography, z.radius_meters)
+                )
+            GROUP BY p.user_id
+        ),
+        candidates AS (
+            SELECT candidate_id FROM mutual
+            UNION
+            SELECT candidate_id FROM nearby
+        )
+        SELECT 
+            u.user_id, u.username, u.profile_image_url,
+            COALESCE(m.mutual_count, 0), COALESCE(n.nearby_count, 0)
+        FROM candidates c
+        JOIN users u ON u.user_id = c.candidate_id
+        LEFT JOIN mutual m ON m.candidate_id = c.candidate_id
+        LEFT JOIN nearby n ON n.candidate_id = c.candidate_id
+        WHERE 
+            c.candidate_id <> $1
+            AND c.candidate_id NOT IN (SELECT related_id FROM related)
+            AND u.is_banned = FALSE
+        -- 絞り込む前に全候補をスコア順に並べる
+        ORDER BY 
+            COALESCE(m.mutual_count, 0) * $5::float8 + LEAST(COALESCE(n.nearby_count, 0), $7::int) * $6::float8 DESC,
+            COALESCE(m.mutual_count, 0) DESC,
+            u.user_id
+        LIMIT $4
+    `
+
+	rows, err := r.client.DB.Query(
+		query,
+		userID,
+		scoring.RecentPinCount,
+		scoring.RadiusMeters,
+		limit,
+		scoring.MutualFriendWeight,
+		scoring.NearbyPinWeight,
+		scoring.NearbyPinCountCap,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query friend suggestions: %w", err)
+	}
+	defer rows.Close()
+
+	suggestions := make([]domain.FriendSuggestion, 0)
+	for rows.Next() {
+		var suggestion domain.FriendSuggestion
+		var profileImageURL sql.NullString
+		if err := rows.Scan(
+			&suggestion.UserID,
+			&suggestion.Username,
+			&profileImageURL,
+			&suggestion.MutualFriendCount,
+			&suggestion.NearbyPinCount,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan friend suggestion: %w", err)
+		}
+		if profileImageURL.Valid {
+			suggestion.ProfileImageURL = profileImageURL.String
 		}
-		friendIDs = append(friendIDs, friendID)
+		suggestions = append(suggestions, suggestion)
 	}
 
 	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("rows iteration error: %w", err)
 	}
 
-	return friendIDs, nil
+	return suggestions, nil
 }
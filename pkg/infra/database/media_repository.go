@@ -0,0 +1,70 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/k-kanke/ashiato-backend/pkg/domain"
+	"github.com/k-kanke/ashiato-backend/pkg/repository"
+)
+
+type postgresMediaRepository struct {
+	client *DBClient
+}
+
+func NewMediaRepository(client *DBClient) repository.MediaRepository {
+	return &postgresMediaRepository{client: client}
+}
+
+func (r *postgresMediaRepository) CreateMedia(media *domain.Media) error {
+	const query = `
+        INSERT INTO media (media_id, user_id, content_type, width, height, size_bytes, storage_key, thumbnail_key, created_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+    `
+
+	_, err := r.client.DB.Exec(
+		query,
+		media.MediaID,
+		media.UserID,
+		media.ContentType,
+		media.Width,
+		media.Height,
+		media.SizeBytes,
+		media.StorageKey,
+		media.ThumbnailKey,
+		media.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert media: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresMediaRepository) FindMediaByID(mediaID string) (*domain.Media, error) {
+	const query = `
+        SELECT media_id, user_id, content_type, width, height, size_bytes, storage_key, thumbnail_key, created_at
+        FROM media
+        WHERE media_id = $1
+    `
+
+	var media domain.Media
+	err := r.client.DB.QueryRow(query, mediaID).Scan(
+		&media.MediaID,
+		&media.UserID,
+		&media.ContentType,
+		&media.Width,
+		&media.Height,
+		&media.SizeBytes,
+		&media.StorageKey,
+		&media.ThumbnailKey,
+		&media.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find media: %w", err)
+	}
+
+	return &media, nil
+}
@@ -0,0 +1,209 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/k-kanke/ashiato-backend/pkg/domain"
+	"github.com/k-kanke/ashiato-backend/pkg/repository"
+	"github.com/lib/pq"
+)
+
+type postgresMFARepository struct {
+	client *DBClient
+}
+
+func NewMFARepository(client *DBClient) repository.MFARepository {
+	return &postgresMFARepository{client: client}
+}
+
+func (r *postgresMFARepository) SavePendingMFA(mfa *domain.UserMFA) (bool, error) {
+	// 登録をやり直した場合は新しい秘密鍵で上書きする
+	const query = `
+        INSERT INTO user_mfa (user_id, secret_encrypted, created_at)
+        VALUES ($1, $2, $3)
+        ON CONFLICT (user_id) DO UPDATE
+        SET secret_encrypted = EXCLUDED.secret_encrypted,
+            last_used_step = 0,
+            failed_attempts = 0,
+            locked_until = NULL,
+            created_at = EXCLUDED.created_at
+        WHERE user_mfa.enabled_at IS NULL
+    `
+
+	result, err := r.client.DB.Exec(query, mfa.UserID, mfa.SecretEncrypted, mfa.CreatedAt)
+	if err != nil {
+		return false, fmt.Errorf("failed to save mfa secret: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rowsAffected > 0, nil
+}
+
+func (r *postgresMFARepository) FindMFA(userID string) (*domain.UserMFA, error) {
+	const query = `
+        SELECT user_id, secret_encrypted, enabled_at, last_used_step, created_at
+        FROM user_mfa
+        WHERE user_id = $1
+    `
+
+	var mfa domain.UserMFA
+	var enabledAt sql.NullTime
+	err := r.client.DB.QueryRow(query, userID).Scan(
+		&mfa.UserID,
+		&mfa.SecretEncrypted,
+		&enabledAt,
+		&mfa.LastUsedStep,
+		&mfa.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find mfa: %w", err)
+	}
+
+	if enabledAt.Valid {
+		mfa.EnabledAt = &enabledAt.Time
+	}
+	return &mfa, nil
+}
+
+func (r *postgresMFARepository) EnableMFA(userID string, step int64, recoveryCodeHashes []string) error {
+	tx, err := r.client.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() // Commit 後の Rollback は何もしない
+
+	const enableQuery = `
+        UPDATE user_mfa
+        SET enabled_at = NOW(), last_used_step = $2
+        WHERE user_id = $1 AND enabled_at IS NULL
+    `
+	if _, err := tx.Exec(enableQuery, userID, step); err != nil {
+		return fmt.Errorf("failed to enable mfa: %w", err)
+	}
+
+	if err := replaceRecoveryCodes(tx, userID, recoveryCodeHashes); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit mfa: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresMFARepository) UpdateLastUsedStep(userID string, step int64) (bool, error) {
+	const query = `
+        UPDATE user_mfa
+        SET last_used_step = $2
+        WHERE user_id = $1 AND last_used_step < $2
+    `
+
+	result, err := r.client.DB.Exec(query, userID, step)
+	if err != nil {
+		return false, fmt.Errorf("failed to update totp step: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rowsAffected > 0, nil
+}
+
+func (r *postgresMFARepository) ReserveMFAAttempt(userID string, maxAttempts int, lockout time.Duration) (bool, error) {
+	// 上限に達したらロックし、ロック解除後は改めて maxAttempts 回まで受け付ける
+	const query = `
+        UPDATE user_mfa
+        SET failed_attempts = CASE WHEN failed_attempts + 1 >= $2 THEN 0 ELSE failed_attempts + 1 END,
+            locked_until = CASE WHEN failed_attempts + 1 >= $2 THEN NOW() + make_interval(secs => $3) ELSE locked_until END
+        WHERE user_id = $1 AND (locked_until IS NULL OR locked_until <= NOW())
+    `
+	result, err := r.client.DB.Exec(query, userID, maxAttempts, lockout.Seconds())
+	if err != nil {
+		return false, fmt.Errorf("failed to reserve mfa attempt: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rowsAffected > 0, nil
+}
+
+func (r *postgresMFARepository) ResetMFAFailures(userID string) error {
+	const query = `
+        UPDATE user_mfa
+        SET failed_attempts = 0, locked_until = NULL
+        WHERE user_id = $1
+    `
+	if _, err := r.client.DB.Exec(query, userID); err != nil {
+		return fmt.Errorf("failed to reset mfa failures: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresMFARepository) ReplaceRecoveryCodes(userID string, recoveryCodeHashes []string) error {
+	tx, err := r.client.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() // Commit 後の Rollback は何もしない
+
+	if err := replaceRecoveryCodes(tx, userID, recoveryCodeHashes); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit recovery codes: %w", err)
+	}
+	return nil
+}
+
+func replaceRecoveryCodes(tx *sql.Tx, userID string, recoveryCodeHashes []string) error {
+	const deleteQuery = `DELETE FROM mfa_recovery_codes WHERE user_id = $1`
+	if _, err := tx.Exec(deleteQuery, userID); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+
+	const insertQuery = `
+        INSERT INTO mfa_recovery_codes (user_id, code_hash)
+        SELECT $1, UNNEST($2::text[])
+        ON CONFLICT DO NOTHING
+    `
+	if _, err := tx.Exec(insertQuery, userID, pq.Array(recoveryCodeHashes)); err != nil {
+		return fmt.Errorf("failed to insert recovery codes: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresMFARepository) ConsumeRecoveryCode(userID, codeHash string) (bool, error) {
+	const query = `
+        UPDATE mfa_recovery_codes
+        SET used_at = NOW()
+        WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+    `
+
+	result, err := r.client.DB.Exec(query, userID, codeHash)
+	if err != nil {
+		return false, fmt.Errorf("failed to consume recovery code: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rowsAffected > 0, nil
+}
+
+func (r *postgresMFARepository) DeleteMFA(userID string) error {
+	// リカバリーコードは外部キーの ON DELETE CASCADE で削除される
+	const query = `DELETE FROM user_mfa WHERE user_id = $1`
+	if _, err := r.client.DB.Exec(query, userID); err != nil {
+		return fmt.Errorf("failed to delete mfa: %w", err)
+	}
+	return nil
+}
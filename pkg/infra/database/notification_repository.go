@@ -0,0 +1,217 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/k-kanke/ashiato-backend/pkg/domain"
+	"github.com/k-kanke/ashiato-backend/pkg/repository"
+	"github.com/k-kanke/ashiato-backend/pkg/shared"
+)
+
+type postgresNotificationRepository struct {
+	client *DBClient
+}
+
+func NewNotificationRepository(client *DBClient) repository.NotificationRepository {
+	return &postgresNotificationRepository{client: client}
+}
+
+func (r *postgresNotificationRepository) CreateNotification(notification *domain.Notification) error {
+	const query = `
+        INSERT INTO notifications 
+            (notification_id, recipient_user_id, actor_user_id, type, related_entity_id, is_read, created_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7)
+    `
+	_, err := r.client.DB.Exec(
+		query,
+		notification.NotificationID,
+		notification.RecipientUserID,
+		nullableUUID(notification.ActorUserID),
+		notification.Type,
+		nullableUUID(notification.RelatedEntityID),
+		notification.IsRead,
+		notification.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert notification: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresNotificationRepository) CreateFriendNewPinNotifications(
+	actorUserID, pinID string,
+	createdAt time.Time,
+) ([]domain.Notification, error) {
+	// acceptedなフレンドのうち、friend_new_pin 設定が有効でピンを閲覧できるユーザーにまとめて通知を作成する
+	const query = `
+        INSERT INTO notifications 
+            (notification_id, recipient_user_id, actor_user_id, type, related_entity_id, is_read, created_at)
+        SELECT 
+            gen_random_uuid(),
+            s.user_id,
+            $1, $2, $3, FALSE, $4
+        FROM friends f
+        JOIN user_settings s 
+            ON s.user_id = CASE WHEN f.user_a_id = $1 THEN f.user_b_id ELSE f.user_a_id END
+        JOIN pins p ON p.pin_id = $3
+        WHERE 
+            (f.user_a_id = $1 OR f.user_b_id = $1)
+            AND f.status = 'accepted'
+            AND s.friend_new_pin = TRUE
+            -- 公開範囲チェック: 本人限定のピンは通知せず、オーディエンス限定のピンはメンバーにのみ通知する
+            AND (
+                p.privacy_setting IN ('public', 'friends')
+                OR (
+                    p.privacy_setting = 'audience'
+                    AND EXISTS (
+                        SELECT 1 FROM audience_members am
+                        WHERE am.audience_id = p.audience_id AND am.member_user_id = s.user_id
+                    )
+                )
+            )
+            -- プライバシーゾーンチェック: 非表示ゾーン内のピンはフレンドにも通知しない
+            AND NOT EXISTS (
+                SELECT 1 FROM privacy_zones z
+                WHERE z.user_id = p.user_id
+                    AND z.mode = 'hide'
+                    AND ST_DWithin(z.center::geography, p.location::geography, z.radius_meters)
+            )
+        RETURNING notification_id, recipient_user_id
+    `
+
+	rows, err := r.client.DB.Query(query, actorUserID, domain.NotificationTypeFriendNewPin, pinID, createdAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert friend pin notifications: %w", err)
+	}
+	defer rows.Close()
+
+	notifications := make([]domain.Notification, 0)
+	for rows.Next() {
+		n := domain.Notification{
+			ActorUserID:     actorUserID,
+			Type:            domain.NotificationTypeFriendNewPin,
+			RelatedEntityID: pinID,
+			CreatedAt:       createdAt,
+		}
+		if err := rows.Scan(&n.NotificationID, &n.RecipientUserID); err != nil {
+			return nil, fmt.Errorf("failed to scan notification: %w", err)
+		}
+		notifications = append(notifications, n)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return notifications, nil
+}
+
+func (r *postgresNotificationRepository) GetNotifications(
+	recipientUserID string,
+	cursor *shared.Cursor,
+	limit int,
+) ([]domain.Notification, error) {
+	// idx_notifications_recipient_created を使って新しい順に取得する
+	query := `
+        SELECT notification_id, recipient_user_id, actor_user_id, type, related_entity_id, is_read, created_at
+        FROM notifications
+        WHERE recipient_user_id = $1
+    `
+	args := []interface{}{recipientUserID}
+	if cursor != nil {
+		query += ` AND (created_at, notification_id) < ($2, $3)`
+		args = append(args, cursor.CreatedAt, cursor.ID)
+	}
+	query += fmt.Sprintf(` ORDER BY created_at DESC, notification_id DESC LIMIT %d`, limit)
+
+	rows, err := r.client.DB.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query notifications: %w", err)
+	}
+	defer rows.Close()
+
+	notifications := make([]domain.Notification, 0)
+	for rows.Next() {
+		var n domain.Notification
+		var actorUserID sql.NullString
+		var relatedEntityID sql.NullString
+		if err := rows.Scan(
+			&n.NotificationID,
+			&n.RecipientUserID,
+			&actorUserID,
+			&n.Type,
+			&relatedEntityID,
+			&n.IsRead,
+			&n.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan notification: %w", err)
+		}
+		if actorUserID.Valid {
+			n.ActorUserID = actorUserID.String
+		}
+		if relatedEntityID.Valid {
+			n.RelatedEntityID = relatedEntityID.String
+		}
+		notifications = append(notifications, n)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return notifications, nil
+}
+
+func (r *postgresNotificationRepository) MarkAsRead(recipientUserID, notificationID string) (bool, error) {
+	const query = `
+        UPDATE notifications
+        SET is_read = TRUE
+        WHERE notification_id = $1 AND recipient_user_id = $2
+    `
+
+	result, err := r.client.DB.Exec(query, notificationID, recipientUserID)
+	if err != nil {
+		return false, fmt.Errorf("failed to mark notification as read: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	// 他人の通知や存在しない通知の場合は false を返す
+	return rowsAffected > 0, nil
+}
+
+func (r *postgresNotificationRepository) MarkAllAsRead(recipientUserID string) error {
+	const query = `
+        UPDATE notifications
+        SET is_read = TRUE
+        WHERE recipient_user_id = $1 AND is_read = FALSE
+    `
+
+	if _, err := r.client.DB.Exec(query, recipientUserID); err != nil {
+		return fmt.Errorf("failed to mark all notifications as read: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresNotificationRepository) CountUnread(recipientUserID string) (int, error) {
+	const query = `SELECT COUNT(*) FROM notifications WHERE recipient_user_id = $1 AND is_read = FALSE`
+
+	var count int
+	if err := r.client.DB.QueryRow(query, recipientUserID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count unread notifications: %w", err)
+	}
+	return count, nil
+}
+
+// nullableUUID は空文字を NULL として扱う
+func nullableUUID(id string) interface{} {
+	if id == "" {
+		return nil
+	}
+	return id
+}
@@ -2,11 +2,14 @@ package database
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/k-kanke/ashiato-backend/pkg/domain"
 	"github.com/k-kanke/ashiato-backend/pkg/repository"
+	"github.com/k-kanke/ashiato-backend/pkg/shared"
+	"github.com/lib/pq"
 )
 
 type postgresPinRepository struct {
@@ -17,14 +20,66 @@ func NewPinRepository(client *DBClient) repository.PinRepository {
 	return &postgresPinRepository{client: client}
 }
 
-func (r *postgresPinRepository) CreatePin(pin *domain.Pin) error {
-	sql := `
-        INSERT INTO pins (pin_id, user_id, location, content_text, media_url, privacy_setting, status, created_at) 
-        VALUES ($1, $2, ST_SetSRID(ST_MakePoint($3, $4), 4326), $5, $6, $7, $8, $9)
+const insertPinSQL = `
+        INSERT INTO pins (pin_id, user_id, location, content_text, media_url, privacy_setting, audience_id, status, is_imported, expires_at, created_at) 
+        VALUES ($1, $2, ST_SetSRID(ST_MakePoint($3, $4), 4326), $5, $6, $7, $8, $9, $10, $11, $12)
     `
+
+func (r *postgresPinRepository) CreatePin(pin *domain.Pin) error {
 	// ST_MakePoint(経度, 緯度) で PostGIS の Point 型を作成
-	_, err := r.client.DB.Exec(
-		sql,
+	_, err := r.client.DB.Exec(insertPinSQL, insertPinArgs(pin)...)
+	if err != nil {
+		return fmt.Errorf("failed to insert pin: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresPinRepository) CreatePins(pins []*domain.Pin) error {
+	tx, err := r.client.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() // Commit 後の Rollback は何もしない
+
+	stmt, err := tx.Prepare(insertPinSQL)
+	if err != nil {
+		return fmt.Errorf("failed to prepare pin insert: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, pin := range pins {
+		if _, err := stmt.Exec(insertPinArgs(pin)...); err != nil {
+			return fmt.Errorf("failed to insert pin: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit pins: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresPinRepository) ExpirePins(now time.Time) (int64, error) {
+	const query = `
+        UPDATE pins
+        SET status = 'expired'
+        WHERE status = 'active' AND expires_at <= $1
+    `
+
+	result, err := r.client.DB.Exec(query, now)
+	if err != nil {
+		return 0, fmt.Errorf("failed to expire pins: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rowsAffected, nil
+}
+
+func insertPinArgs(pin *domain.Pin) []interface{} {
+	return []interface{}{
 		pin.PinID,
 		pin.UserID,
 		pin.Longitude,
@@ -32,13 +87,12 @@ func (r *postgresPinRepository) CreatePin(pin *domain.Pin) error {
 		pin.ContentText,
 		pin.MediaURL,
 		pin.PrivacySetting,
+		nullableUUID(pin.AudienceID),
 		pin.Status,
+		pin.IsImported,
+		pin.ExpiresAt,
 		pin.CreatedAt,
-	)
-	if err != nil {
-		return fmt.Errorf("failed to insert pin: %w", err)
 	}
-	return nil
 }
 
 func (r *postgresPinRepository) GetPinsInArea(
@@ -52,32 +106,24 @@ func (r *postgresPinRepository) GetPinsInArea(
 	// NOTE: SQLの可読性を重視し、ユーザーIDとフレンドシップをチェックする
 	// 複雑なJOINとWHERE句を構築します。
 	sql := `
-        SELECT 
-            p.pin_id, p.user_id, ST_Y(p.location::geometry) AS latitude, ST_X(p.location::geometry) AS longitude,
-            p.content_text, p.media_url, p.privacy_setting, p.created_at
-        FROM pins p
-        -- フレンドシップテーブルをLEFT JOINし、フレンド関係が存在するかチェック
-        LEFT JOIN friends f 
-            ON f.status = 'accepted' 
-            AND (
-                (f.user_a_id = p.user_id AND f.user_b_id = $1) OR 
-                (f.user_b_id = p.user_id AND f.user_a_id = $1)
-            )
+        SELECT ` + visiblePinColumns + `
+        FROM pins p` + pinVisibilityJoin + `
         WHERE 
-            -- 1. ジオメトリ検索: ピンが指定された矩形内にあること
-            ST_Within(p.location::geometry, 
+            -- 1. ジオメトリ検索: 表示位置が指定された矩形内にあること
+            p.location::geometry && ST_Expand(ST_SetSRID(ST_MakeEnvelope($2, $3, $4, $5), 4326), ` + pinFuzzMarginDegrees + `)
+            AND ST_Within(` + pinDisplayLocation + `, 
                 ST_SetSRID(ST_MakeEnvelope($2, $3, $4, $5), 4326)
             )
-            -- 2. 権限チェック:
-            AND (
-                p.privacy_setting = 'public' 
-                OR p.user_id = $1 -- 自分のピンは常に表示 
-                OR (p.privacy_setting = 'friends' AND f.status = 'accepted') -- フレンド限定ピンでフレンド関係がacceptedである
-            )
+            -- 2. 状態チェック: アーカイブ・削除済み・期限切れのピンは地図に表示しない
+            AND ` + pinActiveCondition + `
+            -- 3. 公開範囲チェック
+            AND ` + pinVisibilityCondition + `
+            -- 4. 表示フィルタ
+            AND ` + pinPrivacyFilterCondition("$6") + `
         ORDER BY p.created_at DESC
     `
 
-	rows, err := r.client.DB.Query(sql, userID, minLng, minLat, maxLng, maxLat)
+	rows, err := r.client.DB.Query(sql, userID, minLng, minLat, maxLng, maxLat, privacySetting)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query pins: %w", err)
 	}
@@ -86,16 +132,141 @@ func (r *postgresPinRepository) GetPinsInArea(
 	pins := make([]domain.Pin, 0)
 	for rows.Next() {
 		var pin domain.Pin
+		if err := scanPin(rows, &pin); err != nil {
+			return nil, fmt.Errorf("failed to scan pin: %w", err)
+		}
+		pins = append(pins, pin)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return pins, nil
+}
+
+func (r *postgresPinRepository) GetPinClustersInArea(
+	userID string,
+	minLat, maxLat, minLng, maxLng float64,
+	gridSizeDegrees float64,
+	privacySetting string,
+) ([]domain.PinCluster, error) {
+	// GetPinsInArea と同じ条件で絞り込んだピンを ST_SnapToGrid でグリッドに寄せて集計する
+	query := `
+        WITH visible AS (
+            SELECT 
+                p.pin_id, p.created_at,
+                ` + pinDisplayLocation + ` AS location
+            FROM pins p` + pinVisibilityJoin + `
+            WHERE 
+                p.location::geometry && ST_Expand(ST_SetSRID(ST_MakeEnvelope($2, $3, $4, $5), 4326), ` + pinFuzzMarginDegrees + `)
+                AND ST_Within(` + pinDisplayLocation + `, 
+                    ST_SetSRID(ST_MakeEnvelope($2, $3, $4, $5), 4326)
+                )
+                AND ` + pinActiveCondition + `
+                AND ` + pinVisibilityCondition + `
+                AND ` + pinPrivacyFilterCondition("$7") + `
+        )
+        SELECT 
+            AVG(ST_Y(location)) AS latitude,
+            AVG(ST_X(location)) AS longitude,
+            COUNT(*) AS count,
+            (ARRAY_AGG(pin_id ORDER BY created_at DESC))[1] AS sample_pin_id
+        FROM visible
+        GROUP BY ST_SnapToGrid(location, $6)
+        ORDER BY count DESC
+    `
+
+	rows, err := r.client.DB.Query(query, userID, minLng, minLat, maxLng, maxLat, gridSizeDegrees, privacySetting)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pin clusters: %w", err)
+	}
+	defer rows.Close()
+
+	clusters := make([]domain.PinCluster, 0)
+	for rows.Next() {
+		var cluster domain.PinCluster
 		if err := rows.Scan(
-			&pin.PinID,
-			&pin.UserID,
-			&pin.Latitude,
-			&pin.Longitude,
-			&pin.ContentText,
-			&pin.MediaURL,
-			&pin.PrivacySetting,
-			&pin.CreatedAt,
+			&cluster.Latitude,
+			&cluster.Longitude,
+			&cluster.Count,
+			&cluster.SamplePinID,
 		); err != nil {
+			return nil, fmt.Errorf("failed to scan pin cluster: %w", err)
+		}
+		clusters = append(clusters, cluster)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return clusters, nil
+}
+
+func (r *postgresPinRepository) GetPinTile(userID string, z, x, y int) ([]byte, error) {
+	// ST_TileEnvelope はWebメルカトル (3857) のタイル範囲を返すため、ピンの座標も変換して配置する
+	// 範囲の絞り込みは 4326 に戻したタイル範囲で行い、pins_location_idx を使えるようにする
+	const query = `
+        WITH bounds AS (
+            SELECT ST_TileEnvelope($2, $3, $4) AS geom
+        ),
+        mvtgeom AS (
+            SELECT 
+                ST_AsMVTGeom(ST_Transform(` + pinDisplayLocation + `, 3857), bounds.geom, 4096, 64, true) AS geom,
+                p.pin_id,
+                p.user_id,
+                p.privacy_setting,
+                EXTRACT(EPOCH FROM p.created_at)::bigint AS created_at
+            FROM pins p` + pinVisibilityJoin + `
+            CROSS JOIN bounds
+            WHERE 
+                p.location::geometry && ST_Expand(ST_Transform(bounds.geom, 4326), ` + pinFuzzMarginDegrees + `)
+                AND ` + pinActiveCondition + `
+                AND ` + pinVisibilityCondition + `
+        )
+        SELECT ST_AsMVT(mvtgeom.*, 'pins', 4096, 'geom')
+        FROM mvtgeom
+    `
+
+	var tile []byte
+	if err := r.client.DB.QueryRow(query, userID, z, x, y).Scan(&tile); err != nil {
+		return nil, fmt.Errorf("failed to generate pin tile: %w", err)
+	}
+	return tile, nil
+}
+
+func (r *postgresPinRepository) GetNearbyPins(
+	userID string,
+	lat, lng float64,
+	radiusMeters float64,
+	limit int,
+) ([]domain.NearbyPin, error) {
+	// geography型で比較することで、半径と距離をメートル単位で扱う
+	const query = `
+        SELECT ` + visiblePinColumns + `,
+            ST_Distance((` + pinDisplayLocation + `)::geography, ST_SetSRID(ST_MakePoint($2, $3), 4326)::geography) AS distance_m
+        FROM pins p` + pinVisibilityJoin + `
+        WHERE 
+            -- ぼかした表示位置で半径を判定する（実際の位置での絞り込みはインデックス用）
+            ST_DWithin(p.location::geography, ST_SetSRID(ST_MakePoint($2, $3), 4326)::geography, $4::float8 + ` + pinFuzzMarginMeters + `)
+            AND ST_DWithin((` + pinDisplayLocation + `)::geography, ST_SetSRID(ST_MakePoint($2, $3), 4326)::geography, $4)
+            AND ` + pinActiveCondition + `
+            AND ` + pinVisibilityCondition + `
+        ORDER BY distance_m ASC, p.created_at DESC
+        LIMIT $5
+    `
+
+	rows, err := r.client.DB.Query(query, userID, lng, lat, radiusMeters, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query nearby pins: %w", err)
+	}
+	defer rows.Close()
+
+	pins := make([]domain.NearbyPin, 0)
+	for rows.Next() {
+		var pin domain.NearbyPin
+		if err := scanPin(rows, &pin.Pin, &pin.DistanceMeters); err != nil {
 			return nil, fmt.Errorf("failed to scan pin: %w", err)
 		}
 		pins = append(pins, pin)
@@ -108,6 +279,77 @@ func (r *postgresPinRepository) GetPinsInArea(
 	return pins, nil
 }
 
+func (r *postgresPinRepository) GetVisiblePinsByUser(
+	viewerID, ownerID string,
+	from, to time.Time,
+	limit int,
+) ([]domain.Pin, error) {
+	const query = `
+        SELECT ` + visiblePinColumns + `
+        FROM pins p` + pinVisibilityJoin + `
+        WHERE 
+            p.user_id = $2
+            AND p.created_at >= $3 AND p.created_at < $4
+            -- 削除済みは誰にも見せず、アーカイブ済み・期限切れは所有者にのみ見せる
+            AND ` + pinOwnerReadableCondition + `
+            AND ` + pinVisibilityCondition + `
+        ORDER BY p.created_at ASC
+        LIMIT $5
+    `
+
+	rows, err := r.client.DB.Query(query, viewerID, ownerID, from, to, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query user pins: %w", err)
+	}
+	defer rows.Close()
+
+	pins := make([]domain.Pin, 0)
+	for rows.Next() {
+		var pin domain.Pin
+		if err := scanPin(rows, &pin); err != nil {
+			return nil, fmt.Errorf("failed to scan pin: %w", err)
+		}
+		pins = append(pins, pin)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return pins, nil
+}
+
+func (r *postgresPinRepository) ForEachPinByUser(userID string, fn func(pin *domain.Pin) error) error {
+	const query = `
+        SELECT ` + pinColumns + `
+        FROM pins p
+        WHERE p.user_id = $1 AND p.status <> 'deleted'
+        ORDER BY p.created_at ASC
+    `
+
+	rows, err := r.client.DB.Query(query, userID)
+	if err != nil {
+		return fmt.Errorf("failed to query user pins: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var pin domain.Pin
+		if err := scanPin(rows, &pin); err != nil {
+			return fmt.Errorf("failed to scan pin: %w", err)
+		}
+		if err := fn(&pin); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return nil
+}
+
 func (r *postgresPinRepository) GetMostRecentPin(userID string) (*domain.Pin, error) {
 	const query = `
         SELECT 
@@ -115,7 +357,8 @@ func (r *postgresPinRepository) GetMostRecentPin(userID string) (*domain.Pin, er
             ST_X(p.location::geometry) AS longitude,
             p.created_at
         FROM pins p
-        WHERE p.user_id = $1
+        -- インポートされた過去のピンは現在地の整合性チェックに使わない
+        WHERE p.user_id = $1 AND p.is_imported = FALSE
         ORDER BY p.created_at DESC
         LIMIT 1
     `
@@ -141,7 +384,286 @@ func (r *postgresPinRepository) GetMostRecentPin(userID string) (*domain.Pin, er
 	}, nil
 }
 
+func (r *postgresPinRepository) GetRecentPins(userID string, limit int) ([]domain.Pin, error) {
+	const query = `
+        SELECT ` + pinColumns + `
+        FROM pins p
+        -- インポートされた過去のピンは現在地の整合性チェックに使わない
+        WHERE p.user_id = $1 AND p.is_imported = FALSE
+        ORDER BY p.created_at DESC
+        LIMIT $2
+    `
+
+	rows, err := r.client.DB.Query(query, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query recent pins: %w", err)
+	}
+	defer rows.Close()
+
+	pins := make([]domain.Pin, 0)
+	for rows.Next() {
+		var pin domain.Pin
+		if err := scanPin(rows, &pin); err != nil {
+			return nil, fmt.Errorf("failed to scan pin: %w", err)
+		}
+		pins = append(pins, pin)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return pins, nil
+}
+
+func (r *postgresPinRepository) FindVisiblePin(viewerID, pinID string) (*domain.Pin, error) {
+	// GetPinsInArea と同じ権限チェックを単一ピンに適用する
+	const query = `
+        SELECT ` + visiblePinColumns + `
+        FROM pins p` + pinVisibilityJoin + `
+        WHERE 
+            p.pin_id = $2
+            -- 削除済みは誰にも見せず、アーカイブ済み・期限切れは所有者にのみ見せる
+            AND ` + pinOwnerReadableCondition + `
+            AND ` + pinVisibilityCondition + `
+    `
+
+	var pin domain.Pin
+	if err := scanPin(r.client.DB.QueryRow(query, viewerID, pinID), &pin); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find pin: %w", err)
+	}
+
+	return &pin, nil
+}
+
+func (r *postgresPinRepository) HasVisiblePinWithMedia(viewerID, mediaURL string) (bool, error) {
+	// FindVisiblePin と同じ権限チェックで、メディアを添付したピンを1件でも参照できるかを判定する
+	const query = `
+        SELECT EXISTS (
+            SELECT 1
+            FROM pins p` + pinVisibilityJoin + `
+            WHERE 
+                p.media_url = $2
+                AND ` + pinOwnerReadableCondition + `
+                AND ` + pinVisibilityCondition + `
+        )
+    `
+
+	var exists bool
+	if err := r.client.DB.QueryRow(query, viewerID, mediaURL).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check media visibility: %w", err)
+	}
+
+	return exists, nil
+}
+
+func (r *postgresPinRepository) UpdatePin(pin *domain.Pin) error {
+	const query = `
+        UPDATE pins
+        SET 
+            content_text = $2,
+            media_url = $3,
+            privacy_setting = $4,
+            audience_id = $5,
+            status = $6
+        WHERE pin_id = $1
+    `
+
+	result, err := r.client.DB.Exec(
+		query,
+		pin.PinID,
+		pin.ContentText,
+		pin.MediaURL,
+		pin.PrivacySetting,
+		nullableUUID(pin.AudienceID),
+		pin.Status,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update pin: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return errors.New("pin not found")
+	}
+
+	return nil
+}
+
 func (r *postgresPinRepository) CreateComment(comment *domain.Comment) error {
-	// コメント挿入処理は後で実装予定
+	const query = `
+        INSERT INTO comments (comment_id, pin_id, user_id, content_text, created_at)
+        VALUES ($1, $2, $3, $4, $5)
+    `
+	_, err := r.client.DB.Exec(
+		query,
+		comment.CommentID,
+		comment.PinID,
+		comment.UserID,
+		comment.ContentText,
+		comment.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert comment: %w", err)
+	}
 	return nil
 }
+
+func (r *postgresPinRepository) GetComments(pinID string, cursor *shared.Cursor, limit int) ([]domain.Comment, error) {
+	// idx_comments_pin_created (pin_id, created_at ASC) を使ってスレッド順に取得する
+	// カーソルがある場合は (created_at, comment_id) がカーソルより後ろの行のみ返す
+	query := `
+        SELECT comment_id, pin_id, user_id, content_text, created_at
+        FROM comments
+        WHERE pin_id = $1
+    `
+	args := []interface{}{pinID}
+	if cursor != nil {
+		query += ` AND (created_at, comment_id) > ($2, $3)`
+		args = append(args, cursor.CreatedAt, cursor.ID)
+	}
+	query += fmt.Sprintf(` ORDER BY created_at ASC, comment_id ASC LIMIT %d`, limit)
+
+	rows, err := r.client.DB.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query comments: %w", err)
+	}
+	defer rows.Close()
+
+	comments := make([]domain.Comment, 0)
+	for rows.Next() {
+		var comment domain.Comment
+		if err := rows.Scan(
+			&comment.CommentID,
+			&comment.PinID,
+			&comment.UserID,
+			&comment.ContentText,
+			&comment.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan comment: %w", err)
+		}
+		comments = append(comments, comment)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return comments, nil
+}
+
+func (r *postgresPinRepository) FindCommentByID(commentID string) (*domain.Comment, error) {
+	const query = `
+        SELECT comment_id, pin_id, user_id, content_text, created_at
+        FROM comments
+        WHERE comment_id = $1
+    `
+
+	var comment domain.Comment
+	err := r.client.DB.QueryRow(query, commentID).Scan(
+		&comment.CommentID,
+		&comment.PinID,
+		&comment.UserID,
+		&comment.ContentText,
+		&comment.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find comment: %w", err)
+	}
+
+	return &comment, nil
+}
+
+func (r *postgresPinRepository) DeleteComment(commentID string) error {
+	result, err := r.client.DB.Exec(`DELETE FROM comments WHERE comment_id = $1`, commentID)
+	if err != nil {
+		return fmt.Errorf("failed to delete comment: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return errors.New("comment not found")
+	}
+
+	return nil
+}
+
+func (r *postgresPinRepository) CreateReaction(reaction *domain.Reaction) (bool, error) {
+	const query = `
+        INSERT INTO pin_reactions (pin_id, user_id, emoji, created_at)
+        VALUES ($1, $2, $3, $4)
+        ON CONFLICT (pin_id, user_id, emoji) DO NOTHING
+    `
+
+	result, err := r.client.DB.Exec(query, reaction.PinID, reaction.UserID, reaction.Emoji, reaction.CreatedAt)
+	if err != nil {
+		return false, fmt.Errorf("failed to insert reaction: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rowsAffected > 0, nil
+}
+
+func (r *postgresPinRepository) DeleteReaction(pinID, userID, emoji string) (bool, error) {
+	const query = `DELETE FROM pin_reactions WHERE pin_id = $1 AND user_id = $2 AND emoji = $3`
+
+	result, err := r.client.DB.Exec(query, pinID, userID, emoji)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete reaction: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rowsAffected > 0, nil
+}
+
+func (r *postgresPinRepository) GetReactionCounts(viewerID string, pinIDs []string) ([]domain.ReactionCount, error) {
+	// 主キー (pin_id, user_id, emoji) の先頭列で絞り込むため、ピン数に比例したコストで集計できる
+	const query = `
+        SELECT 
+            pin_id, emoji, COUNT(*) AS count,
+            BOOL_OR(user_id = $1) AS reacted_by_me
+        FROM pin_reactions
+        WHERE pin_id = ANY($2::uuid[])
+        GROUP BY pin_id, emoji
+        ORDER BY pin_id, count DESC, emoji ASC
+    `
+
+	rows, err := r.client.DB.Query(query, viewerID, pq.Array(pinIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query reaction counts: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make([]domain.ReactionCount, 0)
+	for rows.Next() {
+		var count domain.ReactionCount
+		if err := rows.Scan(&count.PinID, &count.Emoji, &count.Count, &count.ReactedByMe); err != nil {
+			return nil, fmt.Errorf("failed to scan reaction count: %w", err)
+		}
+		counts = append(counts, count)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return counts, nil
+}
@@ -0,0 +1,126 @@
+package database
+
+import (
+	"database/sql"
+
+	"github.com/k-kanke/ashiato-backend/pkg/domain"
+)
+
+// 地図・検索・単一取得など、ピンを読み出す全てのクエリで共有する公開範囲チェック。
+// いずれも pins を p として参照し、$1 を閲覧者のユーザーIDとして扱う。
+
+// pinColumns はピンの読み出しに共通する列（scanPin と順序を合わせる）
+const pinColumns = `
+            p.pin_id, p.user_id, ST_Y(p.location::geometry) AS latitude, ST_X(p.location::geometry) AS longitude,
+            p.content_text, p.media_url, p.privacy_setting, p.audience_id, p.status, p.is_imported, p.expires_at, p.created_at`
+
+// visiblePinColumns は閲覧者向けの pinColumns（プライバシーゾーン内のピンはぼかした座標を返す）
+const visiblePinColumns = `
+            p.pin_id, p.user_id, ST_Y(` + pinDisplayLocation + `) AS latitude, ST_X(` + pinDisplayLocation + `) AS longitude,
+            p.content_text, p.media_url, p.privacy_setting, p.audience_id, p.status, p.is_imported, p.expires_at, p.created_at`
+
+// pinActiveCondition は地図・検索に表示する状態のピンの条件。
+// 期限切れのピンはスイーパーが expired に移すが、次の実行までの間も表示しないよう期限を直接判定する
+const pinActiveCondition = `(p.status = 'active' AND (p.expires_at IS NULL OR p.expires_at > NOW()))`
+
+// pinOwnerReadableCondition は単一取得・軌跡など、所有者にはアーカイブ・期限切れのピンも見せるクエリの状態条件
+const pinOwnerReadableCondition = `(` + pinActiveCondition + ` OR (p.status <> 'deleted' AND p.user_id = $1))`
+
+// pinVisibilityJoin はフレンドシップテーブルをLEFT JOINし、投稿者との関係（フレンド・ブロック）を取得する。
+// あわせて、ピンを含む投稿者のプライバシーゾーンを pz として取得する（閲覧者自身のピンは対象外）
+const pinVisibilityJoin = `
+        LEFT JOIN friends f 
+            ON (
+                (f.user_a_id = p.user_id AND f.user_b_id = $1) OR 
+                (f.user_b_id = p.user_id AND f.user_a_id = $1)
+            )
+        LEFT JOIN LATERAL (
+            SELECT z.mode
+            FROM privacy_zones z
+            WHERE z.user_id = p.user_id
+                AND p.user_id <> $1
+                AND ST_DWithin(z.center::geography, p.location::geography, z.radius_meters)
+            -- 非表示とぼかしのゾーンが重なる場合は非表示を優先する
+            ORDER BY (z.mode = 'hide') DESC
+            LIMIT 1
+        ) pz ON TRUE`
+
+// pinDisplayLocation は閲覧者に見せるピンの位置。
+// ぼかしゾーン内のピンは約1.1km (0.01度) のグリッドに寄せたうえで、ピンごとに固定の量 (最大0.005度) だけずらす。
+// ずらす量をリクエストごとに変えると、繰り返し取得して平均を取ることで元の位置を推定できてしまうため固定にしている
+const pinDisplayLocation = `
+            CASE WHEN pz.mode = 'fuzz' THEN ST_SetSRID(ST_MakePoint(
+                ST_X(ST_SnapToGrid(p.location::geometry, 0.01)) + (hashtext(p.pin_id::text || ':lng') % 1000) / 1000.0 * 0.005,
+                ST_Y(ST_SnapToGrid(p.location::geometry, 0.01)) + (hashtext(p.pin_id::text || ':lat') % 1000) / 1000.0 * 0.005
+            ), 4326) ELSE p.location::geometry END`
+
+// pinFuzzMarginDegrees / pinFuzzMarginMeters はぼかしによって表示位置が実際の位置から離れうる最大量。
+// 範囲検索では実際の位置をこの分だけ広げてインデックスで絞り込み、表示位置で改めて判定する
+const (
+	pinFuzzMarginDegrees = `0.01`
+	pinFuzzMarginMeters  = `1600`
+)
+
+// pinVisibilityCondition は閲覧者がピンを参照できる条件（状態のチェックは各クエリで行う）
+const pinVisibilityCondition = `
+            -- ブロックチェック: どちらがブロックしていても互いのピンは表示しない
+            (f.status IS NULL OR f.status <> 'blocked')
+            -- プライバシーゾーンチェック: 非表示ゾーン内のピンは投稿者以外に表示しない
+            AND (pz.mode IS NULL OR pz.mode <> 'hide')
+            -- 権限チェック:
+            AND (
+                p.privacy_setting = 'public' 
+                OR p.user_id = $1 -- 自分のピンは常に表示 
+                OR (p.privacy_setting = 'friends' AND f.status = 'accepted') -- フレンド限定ピンでフレンド関係がacceptedである
+                OR (
+                    -- オーディエンス限定ピンは、リストのメンバーかつacceptedなフレンドにのみ表示
+                    p.privacy_setting = 'audience' AND f.status = 'accepted'
+                    AND EXISTS (
+                        SELECT 1 FROM audience_members am
+                        WHERE am.audience_id = p.audience_id AND am.member_user_id = $1
+                    )
+                )
+            )`
+
+// pinPrivacyFilterCondition は地図の表示フィルタ（all / public / friends / mine）を指定したパラメータで絞り込む条件。
+// 公開範囲のチェックは pinVisibilityCondition で行い、こちらは閲覧可能なピンをさらに絞り込むだけ
+func pinPrivacyFilterCondition(param string) string {
+	return `(
+                ` + param + `::text = 'all'
+                OR (` + param + `::text = 'public' AND p.privacy_setting = 'public')
+                OR (` + param + `::text = 'friends' AND p.user_id <> $1 AND f.status = 'accepted')
+                OR (` + param + `::text = 'mine' AND p.user_id = $1)
+            )`
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanPin は pinColumns の順に読み出し、続く列を extra に読み込む
+func scanPin(row rowScanner, pin *domain.Pin, extra ...interface{}) error {
+	var audienceID sql.NullString
+	var expiresAt sql.NullTime
+	dest := []interface{}{
+		&pin.PinID,
+		&pin.UserID,
+		&pin.Latitude,
+		&pin.Longitude,
+		&pin.ContentText,
+		&pin.MediaURL,
+		&pin.PrivacySetting,
+		&audienceID,
+		&pin.Status,
+		&pin.IsImported,
+		&expiresAt,
+		&pin.CreatedAt,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return err
+	}
+	pin.AudienceID = audienceID.String
+	if expiresAt.Valid {
+		pin.ExpiresAt = &expiresAt.Time
+	}
+	return nil
+}
@@ -0,0 +1,470 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/k-kanke/ashiato-backend/pkg/domain"
+	"github.com/k-kanke/ashiato-backend/pkg/repository"
+)
+
+type postgresTokenRepository struct {
+	client *DBClient
+}
+
+func NewTokenRepository(client *DBClient) repository.TokenRepository {
+	return &postgresTokenRepository{client: client}
+}
+
+const insertRefreshTokenQuery = `
+        INSERT INTO refresh_tokens (token_id, user_id, session_id, token_hash, expires_at, created_at)
+        VALUES ($1, $2, $3, $4, $5, $6)
+    `
+
+func (r *postgresTokenRepository) CreateSession(session *domain.Session, token *domain.RefreshToken) error {
+	tx, err := r.client.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() // Commit 後の Rollback は何もしない
+
+	const sessionQuery = `
+        INSERT INTO sessions (session_id, user_id, user_agent, ip_address, created_at, last_seen_at, expires_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7)
+    `
+	if _, err := tx.Exec(
+		sessionQuery,
+		session.SessionID,
+		session.UserID,
+		session.UserAgent,
+		session.IPAddress,
+		session.CreatedAt,
+		session.LastSeenAt,
+		session.ExpiresAt,
+	); err != nil {
+		return fmt.Errorf("failed to insert session: %w", err)
+	}
+
+	if _, err := tx.Exec(
+		insertRefreshTokenQuery,
+		token.TokenID,
+		token.UserID,
+		token.SessionID,
+		token.TokenHash,
+		token.ExpiresAt,
+		token.CreatedAt,
+	); err != nil {
+		return fmt.Errorf("failed to insert refresh token: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit session: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresTokenRepository) FindRefreshTokenByHash(tokenHash string) (*domain.RefreshToken, error) {
+	const query = `
+        SELECT token_id, user_id, session_id, token_hash, expires_at, used_at, revoked_at, created_at
+        FROM refresh_tokens
+        WHERE token_hash = $1
+    `
+
+	var token domain.RefreshToken
+	var usedAt, revokedAt sql.NullTime
+	err := r.client.DB.QueryRow(query, tokenHash).Scan(
+		&token.TokenID,
+		&token.UserID,
+		&token.SessionID,
+		&token.TokenHash,
+		&token.ExpiresAt,
+		&usedAt,
+		&revokedAt,
+		&token.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find refresh token: %w", err)
+	}
+
+	if usedAt.Valid {
+		token.UsedAt = &usedAt.Time
+	}
+	if revokedAt.Valid {
+		token.RevokedAt = &revokedAt.Time
+	}
+	return &token, nil
+}
+
+func (r *postgresTokenRepository) RotateRefreshToken(oldTokenID string, next *domain.RefreshToken) (bool, error) {
+	tx, err := r.client.DB.Begin()
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() // Commit 後の Rollback は何もしない
+
+	// 同時に同じトークンで更新された場合、先に使用済みにした方だけが成功する
+	const markUsedQuery = `
+        UPDATE refresh_tokens
+        SET used_at = NOW()
+        WHERE token_id = $1 AND used_at IS NULL AND revoked_at IS NULL
+    `
+	result, err := tx.Exec(markUsedQuery, oldTokenID)
+	if err != nil {
+		return false, fmt.Errorf("failed to mark refresh token used: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return false, nil
+	}
+
+	if _, err := tx.Exec(
+		insertRefreshTokenQuery,
+		next.TokenID,
+		next.UserID,
+		next.SessionID,
+		next.TokenHash,
+		next.ExpiresAt,
+		next.CreatedAt,
+	); err != nil {
+		return false, fmt.Errorf("failed to insert refresh token: %w", err)
+	}
+
+	const sessionQuery = `
+        UPDATE sessions
+        SET last_seen_at = $2, expires_at = $3
+        WHERE session_id = $1
+    `
+	if _, err := tx.Exec(sessionQuery, next.SessionID, next.CreatedAt, next.ExpiresAt); err != nil {
+		return false, fmt.Errorf("failed to update session: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit refresh token rotation: %w", err)
+	}
+	return true, nil
+}
+
+func (r *postgresTokenRepository) TouchSession(sessionID string) (bool, error) {
+	// 毎リクエストの書き込みを避けるため、最終利用時刻は1分単位でのみ更新する
+	const query = `
+        WITH touched AS (
+            UPDATE sessions
+            SET last_seen_at = NOW()
+            WHERE session_id = $1
+              AND revoked_at IS NULL
+              AND last_seen_at < NOW() - INTERVAL '1 minute'
+        )
+        SELECT EXISTS (
+            SELECT 1 FROM sessions WHERE session_id = $1 AND revoked_at IS NULL
+        )
+    `
+
+	var active bool
+	if err := r.client.DB.QueryRow(query, sessionID).Scan(&active); err != nil {
+		return false, fmt.Errorf("failed to check session: %w", err)
+	}
+	return active, nil
+}
+
+func (r *postgresTokenRepository) GetActiveSessions(userID string) ([]domain.Session, error) {
+	const query = `
+        SELECT session_id, user_id, user_agent, ip_address, created_at, last_seen_at, expires_at
+        FROM sessions
+        WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > NOW()
+        ORDER BY last_seen_at DESC
+    `
+
+	rows, err := r.client.DB.Query(query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sessions: %w", err)
+	}
+	defer rows.Close()
+
+	sessions := make([]domain.Session, 0)
+	for rows.Next() {
+		var session domain.Session
+		if err := rows.Scan(
+			&session.SessionID,
+			&session.UserID,
+			&session.UserAgent,
+			&session.IPAddress,
+			&session.CreatedAt,
+			&session.LastSeenAt,
+			&session.ExpiresAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan session: %w", err)
+		}
+		sessions = append(sessions, session)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return sessions, nil
+}
+
+func (r *postgresTokenRepository) RevokeSession(userID, sessionID string) (bool, error) {
+	tx, err := r.client.DB.Begin()
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() // Commit 後の Rollback は何もしない
+
+	const sessionQuery = `
+        UPDATE sessions
+        SET revoked_at = NOW()
+        WHERE session_id = $1 AND user_id = $2 AND revoked_at IS NULL
+    `
+	result, err := tx.Exec(sessionQuery, sessionID, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to revoke session: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return false, nil
+	}
+
+	const tokenQuery = `
+        UPDATE refresh_tokens
+        SET revoked_at = NOW()
+        WHERE session_id = $1 AND revoked_at IS NULL
+    `
+	if _, err := tx.Exec(tokenQuery, sessionID); err != nil {
+		return false, fmt.Errorf("failed to revoke refresh tokens: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit session revocation: %w", err)
+	}
+	return true, nil
+}
+
+func (r *postgresTokenRepository) RevokeAllSessions(userID string) error {
+	tx, err := r.client.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() // Commit 後の Rollback は何もしない
+
+	if err := revokeAllSessions(tx, userID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit session revocation: %w", err)
+	}
+	return nil
+}
+
+func revokeAllSessions(tx *sql.Tx, userID string) error {
+	const sessionQuery = `
+        UPDATE sessions
+        SET revoked_at = NOW()
+        WHERE user_id = $1 AND revoked_at IS NULL
+    `
+	if _, err := tx.Exec(sessionQuery, userID); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	const tokenQuery = `
+        UPDATE refresh_tokens
+        SET revoked_at = NOW()
+        WHERE user_id = $1 AND revoked_at IS NULL
+    `
+	if _, err := tx.Exec(tokenQuery, userID); err != nil {
+		return fmt.Errorf("failed to revoke refresh tokens: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresTokenRepository) ResetPasswordWithToken(tokenHash, passwordHash string) (bool, error) {
+	tx, err := r.client.DB.Begin()
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() // Commit 後の Rollback は何もしない
+
+	const consumeQuery = `
+        UPDATE action_tokens
+        SET used_at = NOW()
+        WHERE token_hash = $1 AND purpose = $2 AND used_at IS NULL AND expires_at > NOW()
+        RETURNING user_id
+    `
+	var userID string
+	if err := tx.QueryRow(consumeQuery, tokenHash, domain.ActionTokenPasswordReset).Scan(&userID); err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to consume password reset token: %w", err)
+	}
+
+	// メールのリンクを開けたので、メールアドレスも確認できたことになる
+	const passwordQuery = `
+        UPDATE users
+        SET password_hash = $2, email_verified_at = COALESCE(email_verified_at, NOW()), updated_at = NOW()
+        WHERE user_id = $1
+    `
+	if _, err := tx.Exec(passwordQuery, userID, passwordHash); err != nil {
+		return false, fmt.Errorf("failed to update password: %w", err)
+	}
+
+	// 古いパスワードでログインしている端末を全てログアウトさせる
+	if err := revokeAllSessions(tx, userID); err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit password reset: %w", err)
+	}
+	return true, nil
+}
+
+func (r *postgresTokenRepository) CreateActionToken(token *domain.ActionToken) error {
+	tx, err := r.client.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() // Commit 後の Rollback は何もしない
+
+	if err := insertActionToken(tx, token); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit action token: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresTokenRepository) CreateActionTokenAfterCooldown(token *domain.ActionToken, cooldown time.Duration) (bool, error) {
+	tx, err := r.client.DB.Begin()
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() // Commit 後の Rollback は何もしない
+
+	// 同時に届いたリクエストがどちらもクールダウン外と判定しないよう、ユーザー単位で直列化する
+	const lockQuery = `SELECT 1 FROM users WHERE user_id = $1 FOR UPDATE`
+	if _, err := tx.Exec(lockQuery, token.UserID); err != nil {
+		return false, fmt.Errorf("failed to lock user: %w", err)
+	}
+
+	const recentQuery = `
+        SELECT EXISTS (
+            SELECT 1 FROM action_tokens
+            WHERE user_id = $1 AND purpose = $2 AND created_at > $3
+        )
+    `
+	var recent bool
+	if err := tx.QueryRow(recentQuery, token.UserID, token.Purpose, token.CreatedAt.Add(-cooldown)).Scan(&recent); err != nil {
+		return false, fmt.Errorf("failed to check recent action tokens: %w", err)
+	}
+	if recent {
+		return false, nil
+	}
+
+	if err := insertActionToken(tx, token); err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit action token: %w", err)
+	}
+	return true, nil
+}
+
+func insertActionToken(tx *sql.Tx, token *domain.ActionToken) error {
+	// 最後に送ったメールのリンクだけを有効にする
+	const invalidateQuery = `
+        UPDATE action_tokens
+        SET used_at = NOW()
+        WHERE user_id = $1 AND purpose = $2 AND used_at IS NULL
+    `
+	if _, err := tx.Exec(invalidateQuery, token.UserID, token.Purpose); err != nil {
+		return fmt.Errorf("failed to invalidate action tokens: %w", err)
+	}
+
+	const insertQuery = `
+        INSERT INTO action_tokens (token_hash, user_id, purpose, expires_at, created_at)
+        VALUES ($1, $2, $3, $4, $5)
+    `
+	if _, err := tx.Exec(
+		insertQuery,
+		token.TokenHash,
+		token.UserID,
+		token.Purpose,
+		token.ExpiresAt,
+		token.CreatedAt,
+	); err != nil {
+		return fmt.Errorf("failed to insert action token: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresTokenRepository) ConsumeActionToken(tokenHash, purpose string) (*domain.ActionToken, error) {
+	const query = `
+        UPDATE action_tokens
+        SET used_at = NOW()
+        WHERE token_hash = $1 AND purpose = $2 AND used_at IS NULL AND expires_at > NOW()
+        RETURNING token_hash, user_id, purpose, attempts, expires_at, used_at, created_at
+    `
+
+	var token domain.ActionToken
+	var usedAt time.Time
+	err := r.client.DB.QueryRow(query, tokenHash, purpose).Scan(
+		&token.TokenHash,
+		&token.UserID,
+		&token.Purpose,
+		&token.Attempts,
+		&token.ExpiresAt,
+		&usedAt,
+		&token.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to consume action token: %w", err)
+	}
+
+	token.UsedAt = &usedAt
+	return &token, nil
+}
+
+func (r *postgresTokenRepository) ReserveActionTokenAttempt(tokenHash, purpose string, maxAttempts int) (*domain.ActionToken, error) {
+	const query = `
+        UPDATE action_tokens
+        SET attempts = attempts + 1
+        WHERE token_hash = $1 AND purpose = $2 AND used_at IS NULL AND expires_at > NOW() AND attempts < $3
+        RETURNING token_hash, user_id, purpose, attempts, expires_at, created_at
+    `
+
+	var token domain.ActionToken
+	err := r.client.DB.QueryRow(query, tokenHash, purpose, maxAttempts).Scan(
+		&token.TokenHash,
+		&token.UserID,
+		&token.Purpose,
+		&token.Attempts,
+		&token.ExpiresAt,
+		&token.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to reserve action token attempt: %w", err)
+	}
+
+	return &token, nil
+}
@@ -30,9 +30,9 @@ func (r *postgresUserRepository) CreateUser(user *domain.User, settings *domain.
 	}
 
 	// UserSettingsテーブルへの挿入
-	sqlSettings := `INSERT INTO user_settings (user_id, comment_on_my_pin, friend_new_pin, friend_request_received, friend_request_accepted) 
-                    VALUES ($1, $2, $3, $4, $5)`
-	_, err = r.client.DB.Exec(sqlSettings, settings.UserID, settings.CommentOnMyPin, settings.FriendNewPin, settings.FriendRequestReceived, settings.FriendRequestAccepted)
+	sqlSettings := `INSERT INTO user_settings (user_id, comment_on_my_pin, friend_new_pin, friend_request_received, friend_request_accepted, reaction_on_my_pin) 
+                    VALUES ($1, $2, $3, $4, $5, $6)`
+	_, err = r.client.DB.Exec(sqlSettings, settings.UserID, settings.CommentOnMyPin, settings.FriendNewPin, settings.FriendRequestReceived, settings.FriendRequestAccepted, settings.ReactionOnMyPin)
 	if err != nil {
 		// ユーザー挿入成功後に設定挿入失敗の場合、ロールバック
 		return fmt.Errorf("failed to insert user settings: %w", err)
@@ -45,6 +45,7 @@ func (r *postgresUserRepository) FindUserByEmail(email string) (*domain.User, er
 	user := &domain.User{}
 	var profileImageURL sql.NullString
 	var bio sql.NullString
+	var emailVerifiedAt sql.NullTime
 
 	const query = `
 		SELECT
@@ -55,6 +56,7 @@ func (r *postgresUserRepository) FindUserByEmail(email string) (*domain.User, er
 			profile_image_url,
 			bio,
 			is_banned,
+			email_verified_at,
 			created_at,
 			updated_at
 		FROM users
@@ -68,6 +70,7 @@ func (r *postgresUserRepository) FindUserByEmail(email string) (*domain.User, er
 		&profileImageURL,
 		&bio,
 		&user.IsBanned,
+		&emailVerifiedAt,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -84,6 +87,9 @@ func (r *postgresUserRepository) FindUserByEmail(email string) (*domain.User, er
 	if bio.Valid {
 		user.Bio = bio.String
 	}
+	if emailVerifiedAt.Valid {
+		user.EmailVerifiedAt = &emailVerifiedAt.Time
+	}
 
 	return user, nil
 }
@@ -92,6 +98,7 @@ func (r *postgresUserRepository) FindUserByID(userID string) (*domain.User, *dom
 	user := &domain.User{}
 	var profileImageURL sql.NullString
 	var bio sql.NullString
+	var emailVerifiedAt sql.NullTime
 
 	const userQuery = `
 		SELECT
@@ -102,6 +109,7 @@ func (r *postgresUserRepository) FindUserByID(userID string) (*domain.User, *dom
 			profile_image_url,
 			bio,
 			is_banned,
+			email_verified_at,
 			created_at,
 			updated_at
 		FROM users
@@ -115,6 +123,7 @@ func (r *postgresUserRepository) FindUserByID(userID string) (*domain.User, *dom
 		&profileImageURL,
 		&bio,
 		&user.IsBanned,
+		&emailVerifiedAt,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -131,6 +140,9 @@ func (r *postgresUserRepository) FindUserByID(userID string) (*domain.User, *dom
 	if bio.Valid {
 		user.Bio = bio.String
 	}
+	if emailVerifiedAt.Valid {
+		user.EmailVerifiedAt = &emailVerifiedAt.Time
+	}
 
 	settings := &domain.UserSettings{}
 
@@ -140,7 +152,8 @@ func (r *postgresUserRepository) FindUserByID(userID string) (*domain.User, *dom
 			comment_on_my_pin,
 			friend_new_pin,
 			friend_request_received,
-			friend_request_accepted
+			friend_request_accepted,
+			reaction_on_my_pin
 		FROM user_settings
 		WHERE user_id = $1`
 
@@ -150,6 +163,7 @@ func (r *postgresUserRepository) FindUserByID(userID string) (*domain.User, *dom
 		&settings.FriendNewPin,
 		&settings.FriendRequestReceived,
 		&settings.FriendRequestAccepted,
+		&settings.ReactionOnMyPin,
 	)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -160,3 +174,111 @@ func (r *postgresUserRepository) FindUserByID(userID string) (*domain.User, *dom
 
 	return user, settings, nil
 }
+
+func (r *postgresUserRepository) MarkEmailVerified(userID string) error {
+	const query = `
+		UPDATE users
+		SET email_verified_at = COALESCE(email_verified_at, NOW()), updated_at = NOW()
+		WHERE user_id = $1`
+
+	if _, err := r.client.DB.Exec(query, userID); err != nil {
+		return fmt.Errorf("failed to mark email verified: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresUserRepository) IsEmailVerified(userID string) (bool, error) {
+	const query = `SELECT email_verified_at IS NOT NULL FROM users WHERE user_id = $1`
+
+	var verified bool
+	if err := r.client.DB.QueryRow(query, userID).Scan(&verified); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to check email verification: %w", err)
+	}
+	return verified, nil
+}
+
+func (r *postgresUserRepository) CreatePrivacyZone(zone *domain.PrivacyZone) error {
+	const query = `
+		INSERT INTO privacy_zones (zone_id, user_id, name, center, radius_meters, mode, created_at)
+		VALUES ($1, $2, $3, ST_SetSRID(ST_MakePoint($4, $5), 4326), $6, $7, $8)`
+
+	_, err := r.client.DB.Exec(
+		query,
+		zone.ZoneID,
+		zone.UserID,
+		zone.Name,
+		zone.Longitude,
+		zone.Latitude,
+		zone.RadiusMeters,
+		zone.Mode,
+		zone.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert privacy zone: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresUserRepository) GetPrivacyZones(userID string) ([]domain.PrivacyZone, error) {
+	const query = `
+		SELECT
+			zone_id,
+			user_id,
+			name,
+			ST_Y(center) AS latitude,
+			ST_X(center) AS longitude,
+			radius_meters,
+			mode,
+			created_at
+		FROM privacy_zones
+		WHERE user_id = $1
+		ORDER BY created_at ASC`
+
+	rows, err := r.client.DB.Query(query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query privacy zones: %w", err)
+	}
+	defer rows.Close()
+
+	zones := make([]domain.PrivacyZone, 0)
+	for rows.Next() {
+		var zone domain.PrivacyZone
+		if err := rows.Scan(
+			&zone.ZoneID,
+			&zone.UserID,
+			&zone.Name,
+			&zone.Latitude,
+			&zone.Longitude,
+			&zone.RadiusMeters,
+			&zone.Mode,
+			&zone.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan privacy zone: %w", err)
+		}
+		zones = append(zones, zone)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return zones, nil
+}
+
+func (r *postgresUserRepository) DeletePrivacyZone(userID, zoneID string) (bool, error) {
+	const query = `DELETE FROM privacy_zones WHERE zone_id = $1 AND user_id = $2`
+
+	result, err := r.client.DB.Exec(query, zoneID, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete privacy zone: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rowsAffected > 0, nil
+}
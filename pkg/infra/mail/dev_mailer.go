@@ -0,0 +1,60 @@
+package mail
+
+import (
+	"fmt"
+	"log"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/k-kanke/ashiato-backend/pkg/domain"
+	"github.com/k-kanke/ashiato-backend/pkg/repository"
+)
+
+// logMailer はメールを送らずにログへ出力する（ローカル開発用）
+type logMailer struct{}
+
+func NewLogMailer() repository.Mailer {
+	return logMailer{}
+}
+
+func (logMailer) Send(message *domain.MailMessage) error {
+	log.Printf("mail to %s: %s\n%s", message.To, message.Subject, message.Body)
+	return nil
+}
+
+// fileMailer はメールを .eml ファイルとしてディレクトリに書き出す（ローカル開発用）
+type fileMailer struct {
+	dir  string
+	from *mail.Address
+}
+
+func NewFileMailer(dir, from string) (repository.Mailer, error) {
+	sender, err := mail.ParseAddress(from)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sender address: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create mail directory: %w", err)
+	}
+	return &fileMailer{dir: dir, from: sender}, nil
+}
+
+func (m *fileMailer) Send(message *domain.MailMessage) error {
+	to, err := mail.ParseAddress(message.To)
+	if err != nil {
+		return fmt.Errorf("invalid recipient address: %w", err)
+	}
+
+	data, err := buildMessage(m.from, to, message)
+	if err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%s-%s.eml", time.Now().Format("20060102T150405.000000000"), to.Address)
+	if err := os.WriteFile(filepath.Join(m.dir, name), data, 0o644); err != nil {
+		return fmt.Errorf("failed to write mail: %w", err)
+	}
+	return nil
+}
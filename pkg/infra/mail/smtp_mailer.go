@@ -0,0 +1,105 @@
+package mail
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/k-kanke/ashiato-backend/pkg/domain"
+	"github.com/k-kanke/ashiato-backend/pkg/repository"
+)
+
+// SMTPConfig は SMTP サーバーの接続情報
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string // 空の場合は認証しない
+	Password string
+	From     string
+}
+
+type smtpMailer struct {
+	config SMTPConfig
+	from   *mail.Address
+}
+
+// NewSMTPMailer は SMTP サーバー経由でメールを送る Mailer を返す
+// 認証情報を平文で送らないよう、net/smtp は STARTTLS が使えない接続では PLAIN 認証を拒否する
+func NewSMTPMailer(config SMTPConfig) (repository.Mailer, error) {
+	if config.Host == "" {
+		return nil, fmt.Errorf("SMTP host is required")
+	}
+	from, err := mail.ParseAddress(config.From)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sender address: %w", err)
+	}
+	return &smtpMailer{config: config, from: from}, nil
+}
+
+func (m *smtpMailer) Send(message *domain.MailMessage) error {
+	to, err := mail.ParseAddress(message.To)
+	if err != nil {
+		return fmt.Errorf("invalid recipient address: %w", err)
+	}
+
+	data, err := buildMessage(m.from, to, message)
+	if err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if m.config.Username != "" {
+		auth = smtp.PlainAuth("", m.config.Username, m.config.Password, m.config.Host)
+	}
+
+	addr := net.JoinHostPort(m.config.Host, strconv.Itoa(m.config.Port))
+	if err := smtp.SendMail(addr, auth, m.from.Address, []string{to.Address}, data); err != nil {
+		return fmt.Errorf("failed to send mail: %w", err)
+	}
+	return nil
+}
+
+// buildMessage は UTF-8 のテキストメールを組み立てる
+func buildMessage(from, to *mail.Address, message *domain.MailMessage) ([]byte, error) {
+	var buf bytes.Buffer
+
+	headers := [][2]string{
+		{"From", from.String()},
+		{"To", to.String()},
+		{"Subject", mime.QEncoding.Encode("utf-8", message.Subject)},
+		{"Date", time.Now().Format(time.RFC1123Z)},
+		{"Message-ID", fmt.Sprintf("<%s@%s>", uuid.New().String(), senderDomain(from))},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", "text/plain; charset=UTF-8"},
+		{"Content-Transfer-Encoding", "quoted-printable"},
+	}
+	for _, header := range headers {
+		fmt.Fprintf(&buf, "%s: %s\r\n", header[0], header[1])
+	}
+	buf.WriteString("\r\n")
+
+	body := quotedprintable.NewWriter(&buf)
+	if _, err := body.Write([]byte(strings.ReplaceAll(message.Body, "\n", "\r\n"))); err != nil {
+		return nil, fmt.Errorf("failed to encode mail body: %w", err)
+	}
+	if err := body.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encode mail body: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+func senderDomain(from *mail.Address) string {
+	if at := strings.LastIndex(from.Address, "@"); at >= 0 {
+		return from.Address[at+1:]
+	}
+	return "localhost"
+}
@@ -0,0 +1,77 @@
+package realtime
+
+import (
+	"sync"
+
+	"github.com/k-kanke/ashiato-backend/pkg/domain"
+	"github.com/k-kanke/ashiato-backend/pkg/repository"
+)
+
+// 購読者ごとのバッファサイズ。溢れたイベントは破棄する
+const subscriberBufferSize = 64
+
+type subscriber struct {
+	userID string
+	ch     chan domain.StreamEvent
+}
+
+// inProcessHub は単一ノード向けのインメモリPub/Sub
+type inProcessHub struct {
+	mu          sync.RWMutex
+	subscribers map[*subscriber]struct{}
+}
+
+func NewHub() repository.EventHub {
+	return &inProcessHub{subscribers: make(map[*subscriber]struct{})}
+}
+
+func (h *inProcessHub) Subscribe(userID string) (<-chan domain.StreamEvent, func()) {
+	sub := &subscriber{
+		userID: userID,
+		ch:     make(chan domain.StreamEvent, subscriberBufferSize),
+	}
+
+	h.mu.Lock()
+	h.subscribers[sub] = struct{}{}
+	h.mu.Unlock()
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			h.mu.Lock()
+			delete(h.subscribers, sub)
+			close(sub.ch)
+			h.mu.Unlock()
+		})
+	}
+
+	return sub.ch, unsubscribe
+}
+
+func (h *inProcessHub) PublishToUser(userID string, event domain.StreamEvent) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for sub := range h.subscribers {
+		if sub.userID == userID {
+			send(sub, event)
+		}
+	}
+}
+
+func (h *inProcessHub) Broadcast(event domain.StreamEvent) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for sub := range h.subscribers {
+		send(sub, event)
+	}
+}
+
+// send は購読者の受信が詰まっていても配信元をブロックしない
+func send(sub *subscriber, event domain.StreamEvent) {
+	select {
+	case sub.ch <- event:
+	default:
+	}
+}
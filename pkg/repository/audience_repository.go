@@ -0,0 +1,23 @@
+package repository
+
+import "github.com/k-kanke/ashiato-backend/pkg/domain"
+
+type AudienceRepository interface {
+	// オーディエンスリストとメンバーを作成する
+	CreateAudience(audience *domain.Audience) error
+
+	// IDを基にオーディエンスリストとメンバーを取得する（存在しない場合は nil）
+	FindAudienceByID(audienceID string) (*domain.Audience, error)
+
+	// ユーザーが所有するオーディエンスリストを作成順に取得する
+	GetAudiencesByUser(userID string) ([]domain.Audience, error)
+
+	// オーディエンスリストを削除する（該当がなければ false）
+	DeleteAudience(userID, audienceID string) (bool, error)
+
+	// メンバーを追加する（既にメンバーの場合は何もしない）
+	AddAudienceMember(audienceID, memberID string) error
+
+	// メンバーを削除する（該当がなければ false）
+	RemoveAudienceMember(audienceID, memberID string) (bool, error)
+}
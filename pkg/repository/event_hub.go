@@ -0,0 +1,14 @@
+package repository
+
+import "github.com/k-kanke/ashiato-backend/pkg/domain"
+
+type EventHub interface {
+	// ユーザーの購読を開始する。返却された関数で購読を解除する
+	Subscribe(userID string) (<-chan domain.StreamEvent, func())
+
+	// 特定ユーザーの購読者全員にイベントを配信する
+	PublishToUser(userID string, event domain.StreamEvent)
+
+	// 全ての購読者にイベントを配信する
+	Broadcast(event domain.StreamEvent)
+}
@@ -1,6 +1,9 @@
 package repository
 
-import "github.com/k-kanke/ashiato-backend/pkg/domain"
+import (
+	"github.com/k-kanke/ashiato-backend/pkg/domain"
+	"github.com/k-kanke/ashiato-backend/pkg/shared"
+)
 
 type FriendRepository interface {
 	// フレンド申請を作成する (status='pending')
@@ -12,6 +15,26 @@ type FriendRepository interface {
 	// フレンド申請を承認/拒否/ブロックなどで更新する
 	UpdateFriendshipStatus(userA, userB, newStatus, actionUserID string) error
 
-	// ユーザーIDを元にフレンド一覧を取得する
-	GetFriendsList(userID string) ([]string, error)
+	// 関係が存在しなければ作成し、存在すればステータスを上書きする
+	SaveFriendship(userA, userB, status, actionUserID string) error
+
+	// 関係を削除する（フレンド解除・ブロック解除）
+	DeleteFriendship(userA, userB string) error
+
+	// ユーザーIDを元にフレンド一覧を新しい順に取得する
+	GetFriendsList(userID string, cursor *shared.Cursor, limit int) ([]domain.FriendSummary, error)
+
+	// 自分宛てのフレンド申請（pending）を新しい順に取得する
+	GetIncomingRequests(userID string, cursor *shared.Cursor, limit int) ([]domain.FriendSummary, error)
+
+	// 自分が送ったフレンド申請（pending）を新しい順に取得する
+	GetOutgoingRequests(userID string, cursor *shared.Cursor, limit int) ([]domain.FriendSummary, error)
+
+	// 共通のフレンド数と、自分の最近のピン周辺にある公開ピン数を集計したフレンド候補をスコアの高い順に取得する
+	// 既に何らかの関係（フレンド・申請中・ブロックなど）があるユーザーは含めない
+	GetSuggestionCandidates(
+		userID string,
+		scoring domain.SuggestionScoring,
+		limit int,
+	) ([]domain.FriendSuggestion, error)
 }
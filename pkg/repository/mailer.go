@@ -0,0 +1,8 @@
+package repository
+
+import "github.com/k-kanke/ashiato-backend/pkg/domain"
+
+type Mailer interface {
+	// メールを送信する
+	Send(message *domain.MailMessage) error
+}
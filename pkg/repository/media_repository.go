@@ -0,0 +1,11 @@
+package repository
+
+import "github.com/k-kanke/ashiato-backend/pkg/domain"
+
+type MediaRepository interface {
+	// アップロードされたメディアの情報を保存する
+	CreateMedia(media *domain.Media) error
+
+	// IDを基にメディアの情報を取得する（存在しない場合は nil）
+	FindMediaByID(mediaID string) (*domain.Media, error)
+}
@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"errors"
+	"io"
+)
+
+var ErrMediaObjectNotFound = errors.New("media object not found")
+
+// MediaStorage はアップロードされたメディアファイルの保存先（ローカルディスク・オブジェクトストレージなど）
+type MediaStorage interface {
+	// key にファイルを保存する（同じ key が存在する場合は上書きする）
+	Save(key string, r io.Reader) error
+
+	// key のファイルを開く（存在しない場合は ErrMediaObjectNotFound）
+	Open(key string) (io.ReadCloser, error)
+
+	// key のファイルを削除する
+	Delete(key string) error
+}
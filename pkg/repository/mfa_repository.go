@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/k-kanke/ashiato-backend/pkg/domain"
+)
+
+type MFARepository interface {
+	// 登録途中の TOTP の秘密鍵を保存する（有効化済みの場合は上書きせず false を返す）
+	SavePendingMFA(mfa *domain.UserMFA) (bool, error)
+
+	// ユーザーの二要素認証の設定を取得する（該当がなければ nil）
+	FindMFA(userID string) (*domain.UserMFA, error)
+
+	// 二要素認証を有効化し、リカバリーコードを保存する
+	EnableMFA(userID string, step int64, recoveryCodeHashes []string) error
+
+	// 使われた TOTP のステップを記録する（記録済みのステップ以前であれば false）
+	UpdateLastUsedStep(userID string, step int64) (bool, error)
+
+	// 検証前に試行を失敗として数え、ロック中であれば false を返す
+	// 連続 maxAttempts 回目の試行で lockout の間ロックする。並行したリクエストでも上限を超えて検証できないよう、判定と加算を1文で行う
+	ReserveMFAAttempt(userID string, maxAttempts int, lockout time.Duration) (bool, error)
+
+	// 検証に成功したため、連続失敗の回数とロックをリセットする
+	ResetMFAFailures(userID string) error
+
+	// リカバリーコードを全て置き換える
+	ReplaceRecoveryCodes(userID string, recoveryCodeHashes []string) error
+
+	// 未使用のリカバリーコードを使用済みにする（該当がなければ false）
+	ConsumeRecoveryCode(userID, codeHash string) (bool, error)
+
+	// 二要素認証の設定とリカバリーコードを削除する
+	DeleteMFA(userID string) error
+}
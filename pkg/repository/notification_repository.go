@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/k-kanke/ashiato-backend/pkg/domain"
+	"github.com/k-kanke/ashiato-backend/pkg/shared"
+)
+
+type NotificationRepository interface {
+	// 通知を作成する
+	CreateNotification(notification *domain.Notification) error
+
+	// 新規ピン通知を受け取る設定のフレンド全員に通知を作成し、作成した通知を返す
+	CreateFriendNewPinNotifications(actorUserID, pinID string, createdAt time.Time) ([]domain.Notification, error)
+
+	// 受信者の通知を新しい順に取得する
+	GetNotifications(recipientUserID string, cursor *shared.Cursor, limit int) ([]domain.Notification, error)
+
+	// 通知を既読にする（対象の通知が見つからない場合は false）
+	MarkAsRead(recipientUserID, notificationID string) (bool, error)
+
+	// 受信者の全ての通知を既読にする
+	MarkAllAsRead(recipientUserID string) error
+
+	// 未読通知の件数を取得する
+	CountUnread(recipientUserID string) (int, error)
+}
@@ -1,21 +1,92 @@
 package repository
 
-import "github.com/k-kanke/ashiato-backend/pkg/domain"
+import (
+	"time"
+
+	"github.com/k-kanke/ashiato-backend/pkg/domain"
+	"github.com/k-kanke/ashiato-backend/pkg/shared"
+)
 
 type PinRepository interface {
 	// ピンを作成
 	CreatePin(pin *domain.Pin) error
 
-	// 特定の矩形範囲内のPin情報を取得する
+	// 公開期限を過ぎたアクティブなピンを expired に更新し、更新件数を返す
+	ExpirePins(now time.Time) (int64, error)
+
+	// 複数のピンを1トランザクションで作成する（1件でも失敗した場合は全て取り消す）
+	CreatePins(pins []*domain.Pin) error
+
+	// 特定の矩形範囲内のPin情報を取得する（privacySetting は all / public / friends / mine の表示フィルタ）
 	GetPinsInArea(
 		userID string,
 		minLat, maxLat, minLng, maxLng float64,
 		privacySetting string,
 	) ([]domain.Pin, error)
 
-	// ユーザーの最新のピンを取得する
+	// 特定の矩形範囲内のPinをグリッド単位でクラスタリングして取得する
+	GetPinClustersInArea(
+		userID string,
+		minLat, maxLat, minLng, maxLng float64,
+		gridSizeDegrees float64,
+		privacySetting string,
+	) ([]domain.PinCluster, error)
+
+	// タイル座標 (z/x/y) 内のPinを Mapbox Vector Tile 形式で取得する
+	GetPinTile(userID string, z, x, y int) ([]byte, error)
+
+	// 指定地点から半径内のPin情報を距離の近い順に取得する
+	GetNearbyPins(
+		userID string,
+		lat, lng float64,
+		radiusMeters float64,
+		limit int,
+	) ([]domain.NearbyPin, error)
+
+	// 閲覧者が参照可能な、特定ユーザーの期間内のピンを古い順に取得する
+	GetVisiblePinsByUser(
+		viewerID, ownerID string,
+		from, to time.Time,
+		limit int,
+	) ([]domain.Pin, error)
+
+	// ユーザー自身のピン（削除済みを除く）を古い順に1件ずつ fn に渡す
+	// 全件をメモリに載せないよう、行を読み出しながら処理する
+	ForEachPinByUser(userID string, fn func(pin *domain.Pin) error) error
+
+	// ユーザーの最新のピン（インポート分を除く）を取得する
 	GetMostRecentPin(userID string) (*domain.Pin, error)
 
+	// ユーザーの直近のピン（インポート分を除く）を新しい順に取得する
+	GetRecentPins(userID string, limit int) ([]domain.Pin, error)
+
+	// 閲覧者が参照可能なピンをIDで取得する（存在しない・権限がない場合は nil）
+	FindVisiblePin(viewerID, pinID string) (*domain.Pin, error)
+
+	// 閲覧者が参照可能なピンのうち、指定したメディアを添付したものが存在するか
+	HasVisiblePinWithMedia(viewerID, mediaURL string) (bool, error)
+
+	// ピンの本文・メディア・公開設定・状態を更新する
+	UpdatePin(pin *domain.Pin) error
+
 	// Pinにコメントを追加する
 	CreateComment(comment *domain.Comment) error
+
+	// Pinのコメントをスレッド順（古い順）に取得する
+	GetComments(pinID string, cursor *shared.Cursor, limit int) ([]domain.Comment, error)
+
+	// コメントをIDで取得する
+	FindCommentByID(commentID string) (*domain.Comment, error)
+
+	// コメントを削除する
+	DeleteComment(commentID string) error
+
+	// リアクションを作成する（既に同じリアクションがある場合は false）
+	CreateReaction(reaction *domain.Reaction) (bool, error)
+
+	// リアクションを削除する（該当がなければ false）
+	DeleteReaction(pinID, userID, emoji string) (bool, error)
+
+	// 複数のピンのリアクション数を絵文字ごとに集計する（閲覧者自身のリアクションかどうかも含む）
+	GetReactionCounts(viewerID string, pinIDs []string) ([]domain.ReactionCount, error)
 }
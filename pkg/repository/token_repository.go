@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/k-kanke/ashiato-backend/pkg/domain"
+)
+
+type TokenRepository interface {
+	// セッションと最初のリフレッシュトークンを保存する
+	CreateSession(session *domain.Session, token *domain.RefreshToken) error
+
+	// ハッシュを基にリフレッシュトークンを検索する（該当がなければ nil）
+	FindRefreshTokenByHash(tokenHash string) (*domain.RefreshToken, error)
+
+	// 未使用の旧トークンを使用済みにし、新しいトークンを保存してセッションの最終利用時刻を更新する
+	// 旧トークンが既に使用済み・失効済みの場合は何もせず false を返す
+	RotateRefreshToken(oldTokenID string, next *domain.RefreshToken) (bool, error)
+
+	// セッションが失効していなければ最終利用時刻を更新して true を返す
+	TouchSession(sessionID string) (bool, error)
+
+	// ユーザーの有効なセッションを最終利用時刻の新しい順に取得する
+	GetActiveSessions(userID string) ([]domain.Session, error)
+
+	// セッションとそのリフレッシュトークンを失効させる（該当がなければ false）
+	RevokeSession(userID, sessionID string) (bool, error)
+
+	// ユーザーの全てのセッションとリフレッシュトークンを失効させる
+	RevokeAllSessions(userID string) error
+
+	// 使い捨てトークンを保存する。同じユーザー・用途の未使用トークンは無効になる
+	CreateActionToken(token *domain.ActionToken) error
+
+	// CreateActionToken と同じだが、同じユーザー・用途のトークンを cooldown 以内に発行済みの場合は保存せず false を返す
+	CreateActionTokenAfterCooldown(token *domain.ActionToken, cooldown time.Duration) (bool, error)
+
+	// 未使用・有効期限内のトークンを使用済みにして返す（該当がなければ nil）
+	ConsumeActionToken(tokenHash, purpose string) (*domain.ActionToken, error)
+
+	// 未使用・有効期限内で試行回数が maxAttempts 未満のトークンについて、検証前に試行回数を1つ増やして返す（該当がなければ nil）
+	// 並行したリクエストでも合計 maxAttempts 回までしか検証できないよう、判定と加算を1文で行う
+	ReserveActionTokenAttempt(tokenHash, purpose string, maxAttempts int) (*domain.ActionToken, error)
+
+	// パスワード再設定トークンを使用済みにし、パスワードの更新・メールアドレスの確認・全セッションの失効を1トランザクションで行う
+	// トークンが未使用・有効期限内でなければ何も変更せず false を返す
+	ResetPasswordWithToken(tokenHash, passwordHash string) (bool, error)
+}
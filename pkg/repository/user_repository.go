@@ -15,5 +15,20 @@ type UserRepository interface {
 	// UserIDを基にユーザーと設定を検索する
 	FindUserByID(userID string) (*domain.User, *domain.UserSettings, error)
 
+	// メールアドレスを確認済みにする
+	MarkEmailVerified(userID string) error
+
+	// メールアドレスが確認済みかどうか
+	IsEmailVerified(userID string) (bool, error)
+
+	// プライバシーゾーンを作成する
+	CreatePrivacyZone(zone *domain.PrivacyZone) error
+
+	// ユーザーのプライバシーゾーンを作成順に取得する
+	GetPrivacyZones(userID string) ([]domain.PrivacyZone, error)
+
+	// ユーザーのプライバシーゾーンを削除する（該当がなければ false）
+	DeletePrivacyZone(userID, zoneID string) (bool, error)
+
 	// その他のフレンドや設定更新メソッド
 }
@@ -1,55 +1,115 @@
 package shared
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
 type Claims struct {
-	UserID string `json:"user_id"`
+	UserID    string `json:"user_id"`
+	SessionID string `json:"sid"` // トークンを発行したログインセッション
 	jwt.RegisteredClaims
 }
 
 var ErrInvalidToken = errors.New("invalid or expired token")
 
-func GenerateToken(userID string, secret string, expiryHours int) (string, error) {
-	expirationTime := time.Now().Add(time.Duration(expiryHours) * time.Hour)
+// TokenSigner は KeySet の鍵でアクセストークンを署名・検証する
+// 発行者 (iss) と受信者 (aud) を付与し、検証時にも一致を確認する
+type TokenSigner struct {
+	keys     *KeySet
+	issuer   string
+	audience string
+}
+
+func NewTokenSigner(keys *KeySet, issuer, audience string) *TokenSigner {
+	return &TokenSigner{keys: keys, issuer: issuer, audience: audience}
+}
+
+// GenerateToken はセッションに紐づくアクセストークンを現行の鍵で発行する
+func (s *TokenSigner) GenerateToken(userID, sessionID string, ttl time.Duration) (string, error) {
+	now := time.Now()
 	claims := &Claims{
-		UserID: userID,
+		UserID:    userID,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(expirationTime),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ID:        uuid.New().String(),
+			Subject:   userID,
+			Issuer:    s.issuer,
+			Audience:  jwt.ClaimStrings{s.audience},
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(now),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte(secret))
+	active := s.keys.active
+	token := jwt.NewWithClaims(active.signingMethod(), claims)
+	token.Header["kid"] = active.KeyID
+	tokenString, err := token.SignedString(active.privateKey)
 	if err != nil {
 		return "", err
 	}
 	return tokenString, nil
 }
 
-func ParseToken(tokenString string, secret string) (string, error) {
+// ParseToken は kid の鍵で署名を検証し、有効期限・発行者・受信者を確認してクレームを返す
+// （セッションの失効の判定は呼び出し側で行う）
+func (s *TokenSigner) ParseToken(tokenString string) (*Claims, error) {
 	claims := &Claims{}
 
 	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		keyID, _ := token.Header["kid"].(string)
+		key, ok := s.keys.keys[keyID]
+		if !ok {
 			return nil, ErrInvalidToken
 		}
-		return []byte(secret), nil
-	})
+		// 鍵の種類と異なるアルゴリズムでの検証（アルゴリズム混同攻撃）を拒否する
+		if token.Method.Alg() != key.Algorithm {
+			return nil, ErrInvalidToken
+		}
+		return key.publicKey, nil
+	},
+		jwt.WithValidMethods([]string{AlgorithmRS256, AlgorithmEdDSA}),
+		jwt.WithIssuer(s.issuer),
+		jwt.WithAudience(s.audience),
+		jwt.WithExpirationRequired(),
+	)
 	if err != nil {
-		return "", ErrInvalidToken
+		return nil, ErrInvalidToken
 	}
 	if !token.Valid {
-		return "", ErrInvalidToken
+		return nil, ErrInvalidToken
+	}
+
+	return claims, nil
+}
+
+// JWKS は他のサービスがトークンを検証するための公開鍵一覧を返す
+func (s *TokenSigner) JWKS() *JWKS {
+	return s.keys.JWKS()
+}
+
+// GenerateOpaqueToken はリフレッシュトークンなどに使う推測できないランダムな文字列を生成する
+func GenerateOpaqueToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
 	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
 
-	return claims.UserID, nil
+// HashOpaqueToken はDBに保存するためのトークンのハッシュを返す
+// トークン自体が十分なエントロピーを持つため、ソルトなしの SHA-256 で足りる
+func HashOpaqueToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
 }
 
 func ExtractTokenFromHeader(authHeader string) (string, error) {
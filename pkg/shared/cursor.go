@@ -0,0 +1,53 @@
+package shared
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Cursor はキーセットページネーション用の位置情報（作成日時 + ID）
+type Cursor struct {
+	CreatedAt time.Time
+	ID        string
+}
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// EncodeCursor はカーソルをクライアントに返す不透明な文字列に変換する
+func EncodeCursor(createdAt time.Time, id string) string {
+	raw := createdAt.UTC().Format(time.RFC3339Nano) + "|" + id
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeCursor はクライアントから受け取ったカーソル文字列を復元する
+// 空文字の場合は先頭ページとして nil を返す
+func DecodeCursor(s string) (*Cursor, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	parts := strings.SplitN(string(raw), "|", 2)
+	if len(parts) != 2 {
+		return nil, ErrInvalidCursor
+	}
+	// ID はDBの uuid 列と比較するため、形式が不正なら500ではなく不正なカーソルとして扱う
+	if _, err := uuid.Parse(parts[1]); err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	createdAt, err := time.Parse(time.RFC3339Nano, parts[0])
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	return &Cursor{CreatedAt: createdAt, ID: parts[1]}, nil
+}
@@ -0,0 +1,78 @@
+package geofile
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/k-kanke/ashiato-backend/pkg/domain"
+)
+
+type geoJSONWriter struct {
+	w       io.Writer
+	started bool
+}
+
+func newGeoJSONWriter(w io.Writer) *geoJSONWriter {
+	return &geoJSONWriter{w: w}
+}
+
+type geoJSONFeature struct {
+	Type       string            `json:"type"`
+	Geometry   geoJSONGeometry   `json:"geometry"`
+	Properties geoJSONProperties `json:"properties"`
+}
+
+type geoJSONGeometry struct {
+	Type        string    `json:"type"`
+	Coordinates []float64 `json:"coordinates"` // [経度, 緯度]
+}
+
+type geoJSONProperties struct {
+	PinID          string `json:"pin_id,omitempty"`
+	ContentText    string `json:"content_text"`
+	MediaURL       string `json:"media_url,omitempty"`
+	PrivacySetting string `json:"privacy_setting,omitempty"`
+	CreatedAt      string `json:"created_at"`
+}
+
+func (g *geoJSONWriter) WritePin(pin *domain.Pin) error {
+	prefix := ","
+	if !g.started {
+		prefix = `{"type":"FeatureCollection","features":[`
+		g.started = true
+	}
+
+	feature, err := json.Marshal(geoJSONFeature{
+		Type: "Feature",
+		Geometry: geoJSONGeometry{
+			Type:        "Point",
+			Coordinates: []float64{pin.Longitude, pin.Latitude},
+		},
+		Properties: geoJSONProperties{
+			PinID:          pin.PinID,
+			ContentText:    pin.ContentText,
+			MediaURL:       pin.MediaURL,
+			PrivacySetting: pin.PrivacySetting,
+			CreatedAt:      pin.CreatedAt.UTC().Format(time.RFC3339),
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.WriteString(g.w, prefix); err != nil {
+		return err
+	}
+	_, err = g.w.Write(feature)
+	return err
+}
+
+func (g *geoJSONWriter) Close() error {
+	if !g.started {
+		_, err := io.WriteString(g.w, `{"type":"FeatureCollection","features":[]}`)
+		return err
+	}
+	_, err := io.WriteString(g.w, "]}")
+	return err
+}
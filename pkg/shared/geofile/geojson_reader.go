@@ -0,0 +1,98 @@
+package geofile
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// geoJSONInput は FeatureCollection と単独の Feature の両方を受け付ける
+type geoJSONInput struct {
+	Type     string            `json:"type"`
+	Features []json.RawMessage `json:"features"`
+}
+
+type geoJSONInputFeature struct {
+	Type     string `json:"type"`
+	Geometry *struct {
+		Type        string          `json:"type"`
+		Coordinates json.RawMessage `json:"coordinates"`
+	} `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+func decodeGeoJSON(r io.Reader) ([]Point, []FeatureError, error) {
+	body, err := io.ReadAll(r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var input geoJSONInput
+	if err := json.Unmarshal(body, &input); err != nil {
+		return nil, nil, fmt.Errorf("invalid GeoJSON: %w", err)
+	}
+
+	var rawFeatures []json.RawMessage
+	switch input.Type {
+	case "FeatureCollection":
+		rawFeatures = input.Features
+	case "Feature":
+		rawFeatures = []json.RawMessage{body}
+	default:
+		return nil, nil, fmt.Errorf("invalid GeoJSON: unsupported type %q", input.Type)
+	}
+
+	points := make([]Point, 0, len(rawFeatures))
+	var featureErrors []FeatureError
+	for i, raw := range rawFeatures {
+		point, msg := decodeGeoJSONFeature(i, raw)
+		if msg != "" {
+			featureErrors = append(featureErrors, FeatureError{Index: i, Message: msg})
+			continue
+		}
+		points = append(points, point)
+	}
+
+	return points, featureErrors, nil
+}
+
+func decodeGeoJSONFeature(index int, raw json.RawMessage) (Point, string) {
+	var feature geoJSONInputFeature
+	if err := json.Unmarshal(raw, &feature); err != nil {
+		return Point{}, "invalid feature"
+	}
+	if feature.Geometry == nil || feature.Geometry.Type != "Point" {
+		return Point{}, "only Point geometries are supported"
+	}
+
+	var coordinates []float64
+	if err := json.Unmarshal(feature.Geometry.Coordinates, &coordinates); err != nil || len(coordinates) < 2 {
+		return Point{}, "invalid coordinates"
+	}
+
+	// エクスポート形式 (created_at) と一般的なツールの出力 (time, timestamp) の両方を受け付ける
+	t, err := parseTime(firstString(feature.Properties, "created_at", "time", "timestamp"))
+	if err != nil {
+		return Point{}, err.Error()
+	}
+
+	return Point{
+		Index:          index,
+		Latitude:       coordinates[1],
+		Longitude:      coordinates[0],
+		Time:           t,
+		ContentText:    firstString(feature.Properties, "content_text", "name", "description"),
+		MediaURL:       firstString(feature.Properties, "media_url"),
+		PrivacySetting: firstString(feature.Properties, "privacy_setting"),
+	}, ""
+}
+
+// firstString はプロパティのうち最初に見つかった文字列値を返す
+func firstString(properties map[string]interface{}, keys ...string) string {
+	for _, key := range keys {
+		if value, ok := properties[key].(string); ok && value != "" {
+			return value
+		}
+	}
+	return ""
+}
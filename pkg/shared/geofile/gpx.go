@@ -0,0 +1,76 @@
+package geofile
+
+import (
+	"encoding/xml"
+	"io"
+	"time"
+
+	"github.com/k-kanke/ashiato-backend/pkg/domain"
+)
+
+const gpxHeader = xml.Header +
+	`<gpx version="1.1" creator="ashiato" xmlns="http://www.topografix.com/GPX/1/1">`
+
+type gpxWriter struct {
+	w       io.Writer
+	enc     *xml.Encoder
+	started bool
+}
+
+func newGPXWriter(w io.Writer) *gpxWriter {
+	return &gpxWriter{w: w, enc: xml.NewEncoder(w)}
+}
+
+// gpxWaypoint はピン1件を表す <wpt> 要素
+type gpxWaypoint struct {
+	XMLName xml.Name `xml:"wpt"`
+	Lat     float64  `xml:"lat,attr"`
+	Lon     float64  `xml:"lon,attr"`
+	Time    string   `xml:"time,omitempty"`
+	Name    string   `xml:"name,omitempty"`
+	Desc    string   `xml:"desc,omitempty"`
+	Link    *gpxLink `xml:"link,omitempty"`
+}
+
+type gpxLink struct {
+	Href string `xml:"href,attr"`
+}
+
+func (g *gpxWriter) writeHeader() error {
+	if g.started {
+		return nil
+	}
+	g.started = true
+	_, err := io.WriteString(g.w, gpxHeader)
+	return err
+}
+
+func (g *gpxWriter) WritePin(pin *domain.Pin) error {
+	if err := g.writeHeader(); err != nil {
+		return err
+	}
+
+	wpt := gpxWaypoint{
+		Lat:  pin.Latitude,
+		Lon:  pin.Longitude,
+		Time: pin.CreatedAt.UTC().Format(time.RFC3339),
+		Name: pin.PinID,
+		Desc: pin.ContentText,
+	}
+	if pin.MediaURL != "" {
+		wpt.Link = &gpxLink{Href: pin.MediaURL}
+	}
+
+	if err := g.enc.Encode(wpt); err != nil {
+		return err
+	}
+	return g.enc.Flush()
+}
+
+func (g *gpxWriter) Close() error {
+	if err := g.writeHeader(); err != nil {
+		return err
+	}
+	_, err := io.WriteString(g.w, "</gpx>")
+	return err
+}
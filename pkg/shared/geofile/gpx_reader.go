@@ -0,0 +1,81 @@
+package geofile
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// gpxDocument は読み込み用のGPX構造（ウェイポイント・トラック・ルートの各地点）
+type gpxDocument struct {
+	Waypoints []gpxPoint `xml:"wpt"`
+	Tracks    []struct {
+		Segments []struct {
+			Points []gpxPoint `xml:"trkpt"`
+		} `xml:"trkseg"`
+	} `xml:"trk"`
+	Routes []struct {
+		Points []gpxPoint `xml:"rtept"`
+	} `xml:"rte"`
+}
+
+type gpxPoint struct {
+	Lat  *float64 `xml:"lat,attr"` // 属性がない地点を (0, 0) として取り込まないようポインタで受ける
+	Lon  *float64 `xml:"lon,attr"`
+	Time string   `xml:"time"`
+	Name string   `xml:"name"`
+	Desc string   `xml:"desc"`
+	Link struct {
+		Href string `xml:"href,attr"`
+	} `xml:"link"`
+}
+
+func decodeGPX(r io.Reader) ([]Point, []FeatureError, error) {
+	var doc gpxDocument
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, nil, fmt.Errorf("invalid GPX: %w", err)
+	}
+
+	var raw []gpxPoint
+	raw = append(raw, doc.Waypoints...)
+	for _, trk := range doc.Tracks {
+		for _, seg := range trk.Segments {
+			raw = append(raw, seg.Points...)
+		}
+	}
+	for _, rte := range doc.Routes {
+		raw = append(raw, rte.Points...)
+	}
+
+	points := make([]Point, 0, len(raw))
+	var featureErrors []FeatureError
+	for i, p := range raw {
+		if p.Lat == nil || p.Lon == nil {
+			featureErrors = append(featureErrors, FeatureError{Index: i, Message: "missing lat or lon attribute"})
+			continue
+		}
+
+		t, err := parseTime(p.Time)
+		if err != nil {
+			featureErrors = append(featureErrors, FeatureError{Index: i, Message: err.Error()})
+			continue
+		}
+
+		content := strings.TrimSpace(p.Desc)
+		if content == "" {
+			content = strings.TrimSpace(p.Name)
+		}
+
+		points = append(points, Point{
+			Index:       i,
+			Latitude:    *p.Lat,
+			Longitude:   *p.Lon,
+			Time:        t,
+			ContentText: content,
+			MediaURL:    p.Link.Href,
+		})
+	}
+
+	return points, featureErrors, nil
+}
@@ -0,0 +1,91 @@
+package geofile
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/k-kanke/ashiato-backend/pkg/domain"
+)
+
+const kmlHeader = xml.Header +
+	`<kml xmlns="http://www.opengis.net/kml/2.2"><Document><name>ashiato</name>`
+
+type kmlWriter struct {
+	w       io.Writer
+	enc     *xml.Encoder
+	started bool
+}
+
+func newKMLWriter(w io.Writer) *kmlWriter {
+	return &kmlWriter{w: w, enc: xml.NewEncoder(w)}
+}
+
+// kmlPlacemark はピン1件を表す <Placemark> 要素
+type kmlPlacemark struct {
+	XMLName      xml.Name        `xml:"Placemark"`
+	Name         string          `xml:"name,omitempty"`
+	Description  string          `xml:"description,omitempty"`
+	TimeStamp    kmlTimeStamp    `xml:"TimeStamp"`
+	ExtendedData kmlExtendedData `xml:"ExtendedData"`
+	Point        kmlPoint        `xml:"Point"`
+}
+
+type kmlTimeStamp struct {
+	When string `xml:"when"`
+}
+
+type kmlExtendedData struct {
+	Data []kmlData `xml:"Data"`
+}
+
+type kmlData struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value"`
+}
+
+type kmlPoint struct {
+	Coordinates string `xml:"coordinates"` // "経度,緯度"
+}
+
+func (k *kmlWriter) writeHeader() error {
+	if k.started {
+		return nil
+	}
+	k.started = true
+	_, err := io.WriteString(k.w, kmlHeader)
+	return err
+}
+
+func (k *kmlWriter) WritePin(pin *domain.Pin) error {
+	if err := k.writeHeader(); err != nil {
+		return err
+	}
+
+	placemark := kmlPlacemark{
+		Name:        pin.PinID,
+		Description: pin.ContentText,
+		TimeStamp:   kmlTimeStamp{When: pin.CreatedAt.UTC().Format(time.RFC3339)},
+		ExtendedData: kmlExtendedData{
+			Data: []kmlData{{Name: "privacy_setting", Value: pin.PrivacySetting}},
+		},
+		Point: kmlPoint{Coordinates: fmt.Sprintf("%g,%g", pin.Longitude, pin.Latitude)},
+	}
+	if pin.MediaURL != "" {
+		placemark.ExtendedData.Data = append(placemark.ExtendedData.Data, kmlData{Name: "media_url", Value: pin.MediaURL})
+	}
+
+	if err := k.enc.Encode(placemark); err != nil {
+		return err
+	}
+	return k.enc.Flush()
+}
+
+func (k *kmlWriter) Close() error {
+	if err := k.writeHeader(); err != nil {
+		return err
+	}
+	_, err := io.WriteString(k.w, "</Document></kml>")
+	return err
+}
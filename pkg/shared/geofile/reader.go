@@ -0,0 +1,98 @@
+package geofile
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Point はファイルから読み込んだ1地点分の足あと
+type Point struct {
+	Index          int // ファイル内での出現順（エラー報告用）
+	Latitude       float64
+	Longitude      float64
+	Time           time.Time
+	ContentText    string
+	MediaURL       string
+	PrivacySetting string // ファイルに指定がなければ空
+}
+
+// FeatureError は地点ごとの読み込みエラー
+type FeatureError struct {
+	Index   int    `json:"index"`
+	Message string `json:"error"`
+}
+
+// DetectFormat はファイル名の拡張子から形式を判定する
+func DetectFormat(filename string) string {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".gpx":
+		return FormatGPX
+	case ".geojson", ".json":
+		return FormatGeoJSON
+	default:
+		return ""
+	}
+}
+
+// Decode はファイル全体を読み込み、妥当な地点と地点ごとのエラーを返す
+// ファイル自体が壊れている場合のみ error を返す
+func Decode(format string, r io.Reader) ([]Point, []FeatureError, error) {
+	var points []Point
+	var featureErrors []FeatureError
+	var err error
+
+	switch format {
+	case FormatGPX:
+		points, featureErrors, err = decodeGPX(r)
+	case FormatGeoJSON:
+		points, featureErrors, err = decodeGeoJSON(r)
+	default:
+		return nil, nil, ErrUnsupportedFormat
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// 形式に依らない共通の検証
+	valid := make([]Point, 0, len(points))
+	for _, p := range points {
+		if msg := validatePoint(p); msg != "" {
+			featureErrors = append(featureErrors, FeatureError{Index: p.Index, Message: msg})
+			continue
+		}
+		valid = append(valid, p)
+	}
+
+	return valid, featureErrors, nil
+}
+
+func validatePoint(p Point) string {
+	if math.IsNaN(p.Latitude) || math.IsNaN(p.Longitude) ||
+		p.Latitude < -90 || p.Latitude > 90 || p.Longitude < -180 || p.Longitude > 180 {
+		return "coordinates out of range"
+	}
+	if p.Time.IsZero() {
+		return "missing timestamp"
+	}
+	if p.Time.After(time.Now()) {
+		return "timestamp is in the future"
+	}
+	return ""
+}
+
+// parseTime はファイル中の時刻表記を解釈する
+func parseTime(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid timestamp %q", value)
+	}
+	return t, nil
+}
@@ -0,0 +1,62 @@
+package geofile
+
+import (
+	"errors"
+	"io"
+
+	"github.com/k-kanke/ashiato-backend/pkg/domain"
+)
+
+// 対応しているファイル形式
+const (
+	FormatGeoJSON = "geojson"
+	FormatGPX     = "gpx"
+	FormatKML     = "kml"
+)
+
+var ErrUnsupportedFormat = errors.New("unsupported file format")
+
+// Writer はピンを1件ずつ書き出すストリーミングエンコーダ
+// 全件をメモリに載せずに書き出せるよう、ヘッダは最初の書き込み前に、フッタは Close で出力する
+type Writer interface {
+	WritePin(pin *domain.Pin) error
+	Close() error
+}
+
+// NewWriter は指定形式のエンコーダを返す
+func NewWriter(format string, w io.Writer) (Writer, error) {
+	switch format {
+	case FormatGeoJSON:
+		return newGeoJSONWriter(w), nil
+	case FormatGPX:
+		return newGPXWriter(w), nil
+	case FormatKML:
+		return newKMLWriter(w), nil
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+}
+
+// ContentType は形式に対応する Content-Type を返す
+func ContentType(format string) string {
+	switch format {
+	case FormatGeoJSON:
+		return "application/geo+json"
+	case FormatGPX:
+		return "application/gpx+xml"
+	case FormatKML:
+		return "application/vnd.google-earth.kml+xml"
+	default:
+		return "application/octet-stream"
+	}
+}
+
+// IsSupported は形式に対応しているかを返す
+func IsSupported(format string) bool {
+	switch format {
+	case FormatGeoJSON, FormatGPX, FormatKML:
+		return true
+	default:
+		return false
+	}
+}
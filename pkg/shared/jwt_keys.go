@@ -0,0 +1,180 @@
+package shared
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// 対応する署名アルゴリズム
+const (
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+const minRSAKeyBits = 2048
+
+var ErrInvalidSigningKey = errors.New("invalid signing key")
+
+// SigningKey は kid で識別される署名鍵
+// 秘密鍵を持たない鍵はローテーションで退役した鍵で、検証と JWKS での公開にのみ使う
+type SigningKey struct {
+	KeyID      string
+	Algorithm  string
+	privateKey crypto.Signer
+	publicKey  crypto.PublicKey
+}
+
+// CanSign は秘密鍵を持っているかどうか
+func (k *SigningKey) CanSign() bool {
+	return k.privateKey != nil
+}
+
+func (k *SigningKey) signingMethod() jwt.SigningMethod {
+	if k.Algorithm == AlgorithmEdDSA {
+		return jwt.SigningMethodEdDSA
+	}
+	return jwt.SigningMethodRS256
+}
+
+// ParseSigningKeyPEM は PKCS#8 の秘密鍵、または PKIX の公開鍵（検証専用）を読み込む
+// RSA は RS256、Ed25519 は EdDSA として扱う
+func ParseSigningKeyPEM(keyID string, data []byte) (*SigningKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%w: %s: no PEM block found", ErrInvalidSigningKey, keyID)
+	}
+
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrInvalidSigningKey, keyID, err)
+		}
+		signer, ok := parsed.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s: unsupported private key", ErrInvalidSigningKey, keyID)
+		}
+		key, err := newSigningKey(keyID, signer.Public())
+		if err != nil {
+			return nil, err
+		}
+		key.privateKey = signer
+		return key, nil
+	case "PUBLIC KEY":
+		parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrInvalidSigningKey, keyID, err)
+		}
+		return newSigningKey(keyID, parsed)
+	default:
+		return nil, fmt.Errorf("%w: %s: unsupported PEM type %q", ErrInvalidSigningKey, keyID, block.Type)
+	}
+}
+
+// GenerateEd25519SigningKey はプロセス内でのみ有効な鍵を生成する（ローカル開発用）
+func GenerateEd25519SigningKey(keyID string) (*SigningKey, error) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return &SigningKey{
+		KeyID:      keyID,
+		Algorithm:  AlgorithmEdDSA,
+		privateKey: privateKey,
+		publicKey:  publicKey,
+	}, nil
+}
+
+func newSigningKey(keyID string, publicKey crypto.PublicKey) (*SigningKey, error) {
+	switch pub := publicKey.(type) {
+	case *rsa.PublicKey:
+		if pub.N.BitLen() < minRSAKeyBits {
+			return nil, fmt.Errorf("%w: %s: RSA key must be at least %d bits", ErrInvalidSigningKey, keyID, minRSAKeyBits)
+		}
+		return &SigningKey{KeyID: keyID, Algorithm: AlgorithmRS256, publicKey: pub}, nil
+	case ed25519.PublicKey:
+		return &SigningKey{KeyID: keyID, Algorithm: AlgorithmEdDSA, publicKey: pub}, nil
+	default:
+		return nil, fmt.Errorf("%w: %s: only RSA and Ed25519 keys are supported", ErrInvalidSigningKey, keyID)
+	}
+}
+
+// KeySet は署名に使う現行の鍵と、検証を受け付ける全ての鍵
+type KeySet struct {
+	active *SigningKey
+	keys   map[string]*SigningKey
+}
+
+// NewKeySet は activeKeyID の鍵で署名し、keys の全ての鍵で検証する KeySet を作る
+func NewKeySet(activeKeyID string, keys []*SigningKey) (*KeySet, error) {
+	set := &KeySet{keys: make(map[string]*SigningKey, len(keys))}
+	for _, key := range keys {
+		if key.KeyID == "" {
+			return nil, fmt.Errorf("%w: key id is empty", ErrInvalidSigningKey)
+		}
+		if _, exists := set.keys[key.KeyID]; exists {
+			return nil, fmt.Errorf("%w: duplicate key id %q", ErrInvalidSigningKey, key.KeyID)
+		}
+		set.keys[key.KeyID] = key
+	}
+
+	active, ok := set.keys[activeKeyID]
+	if !ok {
+		return nil, fmt.Errorf("%w: active key %q not found", ErrInvalidSigningKey, activeKeyID)
+	}
+	if !active.CanSign() {
+		return nil, fmt.Errorf("%w: active key %q has no private key", ErrInvalidSigningKey, activeKeyID)
+	}
+	set.active = active
+
+	return set, nil
+}
+
+// JWK は RFC 7517 の公開鍵表現
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+}
+
+// JWKS は /.well-known/jwks.json で公開する鍵の一覧
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS は検証を受け付ける全ての鍵の公開鍵を kid 順に返す
+func (s *KeySet) JWKS() *JWKS {
+	jwks := &JWKS{Keys: make([]JWK, 0, len(s.keys))}
+	for _, key := range s.keys {
+		jwk := JWK{KeyID: key.KeyID, Use: "sig", Algorithm: key.Algorithm}
+		switch pub := key.publicKey.(type) {
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		}
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+	sort.Slice(jwks.Keys, func(i, j int) bool { return jwks.Keys[i].KeyID < jwks.Keys[j].KeyID })
+	return jwks
+}
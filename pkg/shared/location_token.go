@@ -0,0 +1,67 @@
+package shared
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+// LocationTokenClaims は信頼済みクライアントSDKが端末上で測位結果に署名した内容
+type LocationTokenClaims struct {
+	UserID    string  `json:"user_id"`
+	Latitude  float64 `json:"lat"`
+	Longitude float64 `json:"lng"`
+	IssuedAt  int64   `json:"iat"` // UNIX秒
+}
+
+var ErrInvalidLocationToken = errors.New("invalid location token")
+
+// SignLocationToken は "base64url(JSON).base64url(HMAC-SHA256)" 形式の位置トークンを生成する
+func SignLocationToken(claims LocationTokenClaims, secret string) (string, error) {
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + signLocationPayload(encoded, secret), nil
+}
+
+// ParseLocationToken は署名を検証し、トークンの内容を返す（有効期限の判定は呼び出し側で行う）
+func ParseLocationToken(token string, secret string) (*LocationTokenClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 2 {
+		return nil, ErrInvalidLocationToken
+	}
+
+	expected := signLocationPayload(parts[0], secret)
+	if !hmac.Equal([]byte(expected), []byte(parts[1])) {
+		return nil, ErrInvalidLocationToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, ErrInvalidLocationToken
+	}
+
+	var claims LocationTokenClaims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, ErrInvalidLocationToken
+	}
+
+	return &claims, nil
+}
+
+// IssuedAtTime は発行時刻を time.Time で返す
+func (c *LocationTokenClaims) IssuedAtTime() time.Time {
+	return time.Unix(c.IssuedAt, 0)
+}
+
+func signLocationPayload(encodedPayload, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(encodedPayload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
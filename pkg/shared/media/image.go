@@ -0,0 +1,157 @@
+package media
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+)
+
+// アップロードを受け付ける画像形式
+const (
+	ContentTypeJPEG = "image/jpeg"
+	ContentTypePNG  = "image/png"
+)
+
+var (
+	ErrUnsupportedType = errors.New("unsupported image type")
+	ErrInvalidImage    = errors.New("invalid image")
+)
+
+// 展開後のサイズが極端に大きい画像（圧縮爆弾）を拒否するための上限。
+// 一般的なスマートフォンの写真（12MP）まで受け付ける。16bit PNG はデコード後に1画素8バイトになるため、これでも約100MBを使う
+const maxImagePixels = 4032 * 3024
+
+const jpegQuality = 90
+
+// Image はメタデータを取り除いて再エンコードした画像
+type Image struct {
+	ContentType string
+	Width       int
+	Height      int
+	Data        []byte
+}
+
+// Extension はストレージのキーに使う拡張子を返す
+func (img *Image) Extension() string {
+	if img.ContentType == ContentTypePNG {
+		return ".png"
+	}
+	return ".jpg"
+}
+
+// DetectContentType はクライアントの申告ではなく、先頭のバイト列から画像形式を判定する
+func DetectContentType(data []byte) (string, error) {
+	contentType := http.DetectContentType(data)
+	switch contentType {
+	case ContentTypeJPEG, ContentTypePNG:
+		return contentType, nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedType, contentType)
+	}
+}
+
+// Sanitize は画像をデコードして再エンコードする。
+// 画素データのみを書き出すため、EXIF（GPS座標を含む）などのメタデータは全て取り除かれる
+func Sanitize(data []byte) (*Image, image.Image, error) {
+	contentType, err := DetectContentType(data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width*config.Height > maxImagePixels {
+		return nil, nil, fmt.Errorf("%w: %dx%d exceeds %d pixels", ErrInvalidImage, config.Width, config.Height, maxImagePixels)
+	}
+
+	decoded, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+
+	sanitized, err := encode(decoded, contentType)
+	if err != nil {
+		return nil, nil, err
+	}
+	return sanitized, decoded, nil
+}
+
+// Thumbnail は長辺が maxSize 以下になるよう縮小した画像を返す（元画像より大きくはしない）
+func Thumbnail(src image.Image, contentType string, maxSize int) (*Image, error) {
+	return encode(scaleDown(src, maxSize), contentType)
+}
+
+func encode(img image.Image, contentType string) (*Image, error) {
+	var buf bytes.Buffer
+	var err error
+	switch contentType {
+	case ContentTypeJPEG:
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality})
+	case ContentTypePNG:
+		err = png.Encode(&buf, img)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedType, contentType)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode image: %w", err)
+	}
+
+	bounds := img.Bounds()
+	return &Image{
+		ContentType: contentType,
+		Width:       bounds.Dx(),
+		Height:      bounds.Dy(),
+		Data:        buf.Bytes(),
+	}, nil
+}
+
+// scaleDown は縮小先の1画素に対応する元画像の範囲を平均して縮小する（エリア平均法）
+func scaleDown(src image.Image, maxSize int) image.Image {
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width <= maxSize && height <= maxSize {
+		return src
+	}
+
+	dstWidth, dstHeight := maxSize, maxSize
+	if width > height {
+		dstHeight = max(1, height*maxSize/width)
+	} else {
+		dstWidth = max(1, width*maxSize/height)
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+	for y := 0; y < dstHeight; y++ {
+		y0 := bounds.Min.Y + y*height/dstHeight
+		y1 := max(y0+1, bounds.Min.Y+(y+1)*height/dstHeight)
+		for x := 0; x < dstWidth; x++ {
+			x0 := bounds.Min.X + x*width/dstWidth
+			x1 := max(x0+1, bounds.Min.X+(x+1)*width/dstWidth)
+
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := src.At(sx, sy).RGBA()
+					r += uint64(cr)
+					g += uint64(cg)
+					b += uint64(cb)
+					a += uint64(ca)
+					n++
+				}
+			}
+
+			offset := dst.PixOffset(x, y)
+			dst.Pix[offset+0] = uint8(r / n >> 8)
+			dst.Pix[offset+1] = uint8(g / n >> 8)
+			dst.Pix[offset+2] = uint8(b / n >> 8)
+			dst.Pix[offset+3] = uint8(a / n >> 8)
+		}
+	}
+	return dst
+}
@@ -0,0 +1,58 @@
+package shared
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+var ErrDecryptionFailed = errors.New("failed to decrypt secret")
+
+// SecretBox はDBに保存する秘密情報を AES-256-GCM で暗号化する
+type SecretBox struct {
+	aead cipher.AEAD
+}
+
+// NewSecretBox は 32 バイトの鍵から SecretBox を作る
+func NewSecretBox(key []byte) (*SecretBox, error) {
+	if len(key) != 32 {
+		return nil, fmt.Errorf("encryption key must be 32 bytes, got %d", len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &SecretBox{aead: aead}, nil
+}
+
+// Seal は plaintext を暗号化し、"base64url(nonce || ciphertext)" を返す
+// associatedData（ユーザーIDなど）を結び付け、別の行へ暗号文を移しても復号できないようにする
+func (b *SecretBox) Seal(plaintext, associatedData string) (string, error) {
+	nonce := make([]byte, b.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := b.aead.Seal(nonce, nonce, []byte(plaintext), []byte(associatedData))
+	return base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+// Open は Seal で暗号化した値を復号する
+func (b *SecretBox) Open(sealed, associatedData string) (string, error) {
+	data, err := base64.RawURLEncoding.DecodeString(sealed)
+	if err != nil || len(data) < b.aead.NonceSize() {
+		return "", ErrDecryptionFailed
+	}
+	nonce, ciphertext := data[:b.aead.NonceSize()], data[b.aead.NonceSize():]
+	plaintext, err := b.aead.Open(nil, nonce, ciphertext, []byte(associatedData))
+	if err != nil {
+		return "", ErrDecryptionFailed
+	}
+	return string(plaintext), nil
+}
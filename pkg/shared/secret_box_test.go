@@ -0,0 +1,115 @@
+package shared
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"testing"
+)
+
+func newTestSecretBox(t *testing.T, fill byte) *SecretBox {
+	t.Helper()
+	box, err := NewSecretBox(bytes.Repeat([]byte{fill}, 32))
+	if err != nil {
+		t.Fatalf("NewSecretBox() error = %v", err)
+	}
+	return box
+}
+
+func TestNewSecretBox_KeyLength(t *testing.T) {
+	tests := []struct {
+		name    string
+		size    int
+		wantErr bool
+	}{
+		{"empty", 0, true},
+		{"AES-128", 16, true},
+		{"AES-256", 32, false},
+		{"too long", 64, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewSecretBox(make([]byte, tt.size))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewSecretBox(%d bytes) error = %v, wantErr %v", tt.size, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSecretBox_SealOpen(t *testing.T) {
+	box := newTestSecretBox(t, 1)
+	const userID = "8f14e45f-ceea-467f-a0e6-4b1b8f0f5e6a"
+
+	tests := []string{"", "JBSWY3DPEHPK3PXP", "日本語の秘密情報"}
+	for _, plaintext := range tests {
+		sealed, err := box.Seal(plaintext, userID)
+		if err != nil {
+			t.Fatalf("Seal(%q) error = %v", plaintext, err)
+		}
+		opened, err := box.Open(sealed, userID)
+		if err != nil {
+			t.Fatalf("Open(Seal(%q)) error = %v", plaintext, err)
+		}
+		if opened != plaintext {
+			t.Errorf("Open(Seal(%q)) = %q", plaintext, opened)
+		}
+	}
+}
+
+func TestSecretBox_SealUsesFreshNonce(t *testing.T) {
+	box := newTestSecretBox(t, 1)
+
+	first, err := box.Seal("secret", "user")
+	if err != nil {
+		t.Fatalf("Seal() error = %v", err)
+	}
+	second, err := box.Seal("secret", "user")
+	if err != nil {
+		t.Fatalf("Seal() error = %v", err)
+	}
+	if first == second {
+		t.Errorf("Seal() returned the same ciphertext twice")
+	}
+}
+
+func TestSecretBox_OpenFailures(t *testing.T) {
+	box := newTestSecretBox(t, 1)
+	const userID = "8f14e45f-ceea-467f-a0e6-4b1b8f0f5e6a"
+
+	sealed, err := box.Seal("JBSWY3DPEHPK3PXP", userID)
+	if err != nil {
+		t.Fatalf("Seal() error = %v", err)
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(sealed)
+	if err != nil {
+		t.Fatalf("sealed value is not base64url: %v", err)
+	}
+	tampered := append([]byte(nil), raw...)
+	tampered[len(tampered)-1] ^= 0x01
+
+	tests := []struct {
+		name   string
+		box    *SecretBox
+		sealed string
+		aad    string
+	}{
+		{"wrong user id", box, sealed, "c9f0f895-fb98-4b91-9b1f-5c8e2e7e3b4d"},
+		{"empty user id", box, sealed, ""},
+		{"wrong key", newTestSecretBox(t, 2), sealed, userID},
+		{"tampered ciphertext", box, base64.RawURLEncoding.EncodeToString(tampered), userID},
+		{"truncated", box, base64.RawURLEncoding.EncodeToString(raw[:box.aead.NonceSize()-1]), userID},
+		{"not base64", box, "!!!", userID},
+		{"empty", box, "", userID},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opened, err := tt.box.Open(tt.sealed, tt.aad)
+			if !errors.Is(err, ErrDecryptionFailed) {
+				t.Fatalf("Open() = %q, %v; want ErrDecryptionFailed", opened, err)
+			}
+		})
+	}
+}
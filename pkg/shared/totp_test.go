@@ -0,0 +1,110 @@
+package shared
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// RFC 6238 Appendix B の SHA1 用の秘密鍵 "12345678901234567890" を Base32 にしたもの
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestValidateTOTP_RFC6238Vectors(t *testing.T) {
+	// RFC 6238 の8桁のコードの下6桁（6桁の HOTP は同じ値を 10^6 で割った余り）
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+
+	for _, tt := range tests {
+		step, ok := ValidateTOTP(rfc6238Secret, tt.code, time.Unix(tt.unix, 0))
+		if !ok {
+			t.Errorf("ValidateTOTP(t=%d, %s) = false, want true", tt.unix, tt.code)
+			continue
+		}
+		if want := tt.unix / totpPeriod; step != want {
+			t.Errorf("ValidateTOTP(t=%d, %s) step = %d, want %d", tt.unix, tt.code, step, want)
+		}
+	}
+}
+
+func TestValidateTOTP_SkewWindow(t *testing.T) {
+	// t=59 のコード（ステップ1）を、前後のステップの時刻で検証する
+	const code = "287082"
+	tests := []struct {
+		name string
+		unix int64
+		ok   bool
+	}{
+		{"two steps early", 0 - totpPeriod, false},
+		{"one step early", 0, true},
+		{"same step", 59, true},
+		{"one step late", 60, true},
+		{"last second of one step late", 89, true},
+		{"two steps late", 90, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok := ValidateTOTP(rfc6238Secret, code, time.Unix(tt.unix, 0))
+			if ok != tt.ok {
+				t.Fatalf("ValidateTOTP(t=%d) = %v, want %v", tt.unix, ok, tt.ok)
+			}
+			if ok && step != 1 {
+				t.Errorf("ValidateTOTP(t=%d) step = %d, want 1", tt.unix, step)
+			}
+		})
+	}
+}
+
+func TestValidateTOTP_RejectsInvalidInput(t *testing.T) {
+	now := time.Unix(59, 0)
+	tests := []struct {
+		name   string
+		secret string
+		code   string
+		ok     bool
+	}{
+		{"lowercase secret", strings.ToLower(rfc6238Secret), "287082", true},
+		{"wrong code", rfc6238Secret, "287083", false},
+		{"eight digit code", rfc6238Secret, "94287082", false},
+		{"short code", rfc6238Secret, "28708", false},
+		{"empty code", rfc6238Secret, "", false},
+		{"invalid secret", "not base32!", "287082", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, ok := ValidateTOTP(tt.secret, tt.code, now); ok != tt.ok {
+				t.Errorf("ValidateTOTP(%q, %q) = %v, want %v", tt.secret, tt.code, ok, tt.ok)
+			}
+		})
+	}
+}
+
+func TestGenerateTOTPSecret_RoundTrip(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatalf("GenerateTOTPSecret() error = %v", err)
+	}
+	key, err := totpEncoding.DecodeString(secret)
+	if err != nil {
+		t.Fatalf("secret %q is not Base32: %v", secret, err)
+	}
+	if len(key) != 20 {
+		t.Fatalf("secret length = %d bytes, want 20", len(key))
+	}
+
+	now := time.Now()
+	code := totpCode(key, now.Unix()/totpPeriod)
+	if _, ok := ValidateTOTP(secret, code, now); !ok {
+		t.Errorf("ValidateTOTP rejected the current code for a generated secret")
+	}
+}
@@ -0,0 +1,185 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/k-kanke/ashiato-backend/pkg/domain"
+	"github.com/k-kanke/ashiato-backend/pkg/repository"
+)
+
+type AudienceUsecase interface {
+	// オーディエンスリストを作成する（メンバーはacceptedなフレンドのみ）
+	CreateAudience(userID, name string, memberIDs []string) (*domain.Audience, error)
+
+	// 自分のオーディエンスリスト一覧を取得する
+	GetAudiences(userID string) ([]domain.Audience, error)
+
+	// オーディエンスリストを削除する
+	DeleteAudience(userID, audienceID string) error
+
+	// メンバーを追加する
+	AddMember(userID, audienceID, memberID string) (*domain.Audience, error)
+
+	// メンバーを削除する
+	RemoveMember(userID, audienceID, memberID string) (*domain.Audience, error)
+}
+
+type audienceUsecase struct {
+	audienceRepo repository.AudienceRepository
+	friendRepo   repository.FriendRepository
+}
+
+func NewAudienceUsecase(ar repository.AudienceRepository, fr repository.FriendRepository) AudienceUsecase {
+	return &audienceUsecase{audienceRepo: ar, friendRepo: fr}
+}
+
+var (
+	ErrInvalidAudience         = errors.New("invalid audience")
+	ErrAudienceNotFound        = errors.New("audience not found")
+	ErrAudienceMemberNotFriend = errors.New("audience members must be friends")
+	ErrAudienceLimitReached    = errors.New("audience limit reached")
+)
+
+const (
+	maxAudiencesPerUser = 20
+	maxAudienceMembers  = 500
+)
+
+func (u *audienceUsecase) CreateAudience(userID, name string, memberIDs []string) (*domain.Audience, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, fmt.Errorf("%w: name is empty", ErrInvalidAudience)
+	}
+	if len(memberIDs) > maxAudienceMembers {
+		return nil, fmt.Errorf("%w: too many members (max %d)", ErrInvalidAudience, maxAudienceMembers)
+	}
+
+	audiences, err := u.audienceRepo.GetAudiencesByUser(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve audiences: %w", err)
+	}
+	if len(audiences) >= maxAudiencesPerUser {
+		return nil, ErrAudienceLimitReached
+	}
+
+	members := make([]string, 0, len(memberIDs))
+	seen := make(map[string]bool, len(memberIDs))
+	for _, memberID := range memberIDs {
+		if seen[memberID] {
+			continue
+		}
+		seen[memberID] = true
+		if err := u.ensureFriend(userID, memberID); err != nil {
+			return nil, err
+		}
+		members = append(members, memberID)
+	}
+
+	audience := &domain.Audience{
+		AudienceID: uuid.New().String(),
+		UserID:     userID,
+		Name:       name,
+		MemberIDs:  members,
+		CreatedAt:  time.Now(),
+	}
+
+	if err := u.audienceRepo.CreateAudience(audience); err != nil {
+		return nil, fmt.Errorf("audience creation failed: %w", err)
+	}
+
+	return audience, nil
+}
+
+func (u *audienceUsecase) GetAudiences(userID string) ([]domain.Audience, error) {
+	audiences, err := u.audienceRepo.GetAudiencesByUser(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve audiences: %w", err)
+	}
+	return audiences, nil
+}
+
+func (u *audienceUsecase) DeleteAudience(userID, audienceID string) error {
+	if _, err := u.getOwnAudience(userID, audienceID); err != nil {
+		return err
+	}
+
+	deleted, err := u.audienceRepo.DeleteAudience(userID, audienceID)
+	if err != nil {
+		return fmt.Errorf("audience deletion failed: %w", err)
+	}
+	if !deleted {
+		return ErrAudienceNotFound
+	}
+	return nil
+}
+
+func (u *audienceUsecase) AddMember(userID, audienceID, memberID string) (*domain.Audience, error) {
+	audience, err := u.getOwnAudience(userID, audienceID)
+	if err != nil {
+		return nil, err
+	}
+	if len(audience.MemberIDs) >= maxAudienceMembers {
+		return nil, fmt.Errorf("%w: too many members (max %d)", ErrInvalidAudience, maxAudienceMembers)
+	}
+	if err := u.ensureFriend(userID, memberID); err != nil {
+		return nil, err
+	}
+
+	if err := u.audienceRepo.AddAudienceMember(audienceID, memberID); err != nil {
+		return nil, fmt.Errorf("failed to add audience member: %w", err)
+	}
+
+	return u.getOwnAudience(userID, audienceID)
+}
+
+func (u *audienceUsecase) RemoveMember(userID, audienceID, memberID string) (*domain.Audience, error) {
+	if _, err := u.getOwnAudience(userID, audienceID); err != nil {
+		return nil, err
+	}
+
+	removed, err := u.audienceRepo.RemoveAudienceMember(audienceID, memberID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to remove audience member: %w", err)
+	}
+	if !removed {
+		return nil, fmt.Errorf("%w: not a member", ErrAudienceNotFound)
+	}
+
+	return u.getOwnAudience(userID, audienceID)
+}
+
+// getOwnAudience は所有者本人のリストのみを返す（他人のリストは存在しないものとして扱う）
+func (u *audienceUsecase) getOwnAudience(userID, audienceID string) (*domain.Audience, error) {
+	if _, err := uuid.Parse(audienceID); err != nil {
+		return nil, ErrAudienceNotFound
+	}
+
+	audience, err := u.audienceRepo.FindAudienceByID(audienceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve audience: %w", err)
+	}
+	if audience == nil || audience.UserID != userID {
+		return nil, ErrAudienceNotFound
+	}
+	return audience, nil
+}
+
+// ensureFriend はメンバー候補がacceptedなフレンドであることを確認する
+func (u *audienceUsecase) ensureFriend(userID, memberID string) error {
+	if _, err := uuid.Parse(memberID); err != nil || memberID == userID {
+		return fmt.Errorf("%w: %s", ErrAudienceMemberNotFriend, memberID)
+	}
+
+	friendship, err := u.friendRepo.FindFriendshipStatus(userID, memberID)
+	if err != nil {
+		return fmt.Errorf("failed to check friendship status: %w", err)
+	}
+	if friendship == nil || friendship.Status != domain.FriendshipStatusAccepted {
+		return fmt.Errorf("%w: %s", ErrAudienceMemberNotFriend, memberID)
+	}
+	return nil
+}
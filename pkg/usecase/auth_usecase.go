@@ -0,0 +1,259 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/k-kanke/ashiato-backend/pkg/domain"
+	"github.com/k-kanke/ashiato-backend/pkg/repository"
+	"github.com/k-kanke/ashiato-backend/pkg/shared"
+)
+
+type AuthUsecase interface {
+	// ログイン時に新しいセッションを作り、アクセストークンとリフレッシュトークンを発行する
+	IssueTokens(userID string, client ClientInfo) (*AuthTokens, error)
+
+	// リフレッシュトークンをローテーションし、新しいトークンの組を返す
+	RefreshTokens(refreshToken string) (*AuthTokens, error)
+
+	// 現在のセッションを失効させる
+	Logout(claims *shared.Claims) error
+
+	// アクセストークンの署名・有効期限とセッションの失効を検証し、クレームを返す
+	AuthenticateAccessToken(accessToken string) (*shared.Claims, error)
+
+	// 検証済みのクレームについて、トークンの有効期限とセッションの失効を改めて確認する（長時間の接続向け）
+	VerifySession(claims *shared.Claims) error
+
+	// 有効なセッションの一覧を取得する
+	GetSessions(userID, currentSessionID string) ([]domain.Session, error)
+
+	// 指定したセッションを失効させる
+	RevokeSession(userID, sessionID string) error
+
+	// 全ての端末からログアウトする
+	RevokeAllSessions(userID string) error
+
+	// アクセストークンを検証するための公開鍵一覧を返す
+	GetJWKS() *shared.JWKS
+}
+
+// ClientInfo はセッション一覧で端末を見分けるための情報
+type ClientInfo struct {
+	UserAgent string
+	IPAddress string
+}
+
+// AuthTokens はログイン・トークン更新時にクライアントへ返すトークンの組
+type AuthTokens struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"` // アクセストークンの有効秒数
+}
+
+// TokenConfig はトークンの有効期間
+type TokenConfig struct {
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+}
+
+func DefaultTokenConfig() TokenConfig {
+	return TokenConfig{
+		AccessTokenTTL:  15 * time.Minute,
+		RefreshTokenTTL: 30 * 24 * time.Hour,
+	}
+}
+
+type authUsecase struct {
+	tokenRepo repository.TokenRepository
+	signer    *shared.TokenSigner
+	config    TokenConfig
+}
+
+func NewAuthUsecase(tokenRepo repository.TokenRepository, signer *shared.TokenSigner, config TokenConfig) AuthUsecase {
+	return &authUsecase{tokenRepo: tokenRepo, signer: signer, config: config}
+}
+
+var (
+	ErrInvalidAccessToken  = errors.New("invalid or expired token")
+	ErrSessionRevoked      = errors.New("session has been revoked")
+	ErrSessionNotFound     = errors.New("session not found")
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token reuse detected")
+)
+
+const maxUserAgentLength = 255
+
+func (u *authUsecase) IssueTokens(userID string, client ClientInfo) (*AuthTokens, error) {
+	sessionID := uuid.New().String()
+	refreshToken, record, err := u.newRefreshToken(userID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	userAgent := client.UserAgent
+	if len(userAgent) > maxUserAgentLength {
+		userAgent = strings.ToValidUTF8(userAgent[:maxUserAgentLength], "")
+	}
+	session := &domain.Session{
+		SessionID:  sessionID,
+		UserID:     userID,
+		UserAgent:  userAgent,
+		IPAddress:  client.IPAddress,
+		CreatedAt:  record.CreatedAt,
+		LastSeenAt: record.CreatedAt,
+		ExpiresAt:  record.ExpiresAt,
+	}
+	if err := u.tokenRepo.CreateSession(session, record); err != nil {
+		return nil, fmt.Errorf("session creation failed: %w", err)
+	}
+
+	return u.tokensFor(userID, sessionID, refreshToken)
+}
+
+// RefreshTokens は使用済みのリフレッシュトークンが再提示された場合、
+// トークンが漏洩したとみなしてセッションごと失効させる
+func (u *authUsecase) RefreshTokens(refreshToken string) (*AuthTokens, error) {
+	current, err := u.tokenRepo.FindRefreshTokenByHash(shared.HashOpaqueToken(refreshToken))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find refresh token: %w", err)
+	}
+	if current == nil || current.RevokedAt != nil || !current.ExpiresAt.After(time.Now()) {
+		return nil, ErrInvalidRefreshToken
+	}
+	if current.UsedAt != nil {
+		return nil, u.revokeReusedSession(current)
+	}
+
+	nextToken, next, err := u.newRefreshToken(current.UserID, current.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	rotated, err := u.tokenRepo.RotateRefreshToken(current.TokenID, next)
+	if err != nil {
+		return nil, fmt.Errorf("refresh token rotation failed: %w", err)
+	}
+	if !rotated {
+		// 検索してからローテーションするまでの間に、同じトークンが使われた
+		return nil, u.revokeReusedSession(current)
+	}
+
+	return u.tokensFor(current.UserID, current.SessionID, nextToken)
+}
+
+func (u *authUsecase) Logout(claims *shared.Claims) error {
+	if _, err := u.tokenRepo.RevokeSession(claims.UserID, claims.SessionID); err != nil {
+		return fmt.Errorf("logout failed: %w", err)
+	}
+	return nil
+}
+
+func (u *authUsecase) AuthenticateAccessToken(accessToken string) (*shared.Claims, error) {
+	claims, err := u.signer.ParseToken(accessToken)
+	if err != nil {
+		return nil, ErrInvalidAccessToken
+	}
+	// セッションに紐づかないトークンは失効させられないため受け付けない
+	if _, err := uuid.Parse(claims.SessionID); err != nil {
+		return nil, ErrInvalidAccessToken
+	}
+
+	if err := u.VerifySession(claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+func (u *authUsecase) VerifySession(claims *shared.Claims) error {
+	if claims.ExpiresAt == nil || !claims.ExpiresAt.After(time.Now()) {
+		return ErrInvalidAccessToken
+	}
+
+	active, err := u.tokenRepo.TouchSession(claims.SessionID)
+	if err != nil {
+		return fmt.Errorf("session check failed: %w", err)
+	}
+	if !active {
+		return ErrSessionRevoked
+	}
+	return nil
+}
+
+func (u *authUsecase) GetSessions(userID, currentSessionID string) ([]domain.Session, error) {
+	sessions, err := u.tokenRepo.GetActiveSessions(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve sessions: %w", err)
+	}
+	for i := range sessions {
+		sessions[i].Current = sessions[i].SessionID == currentSessionID
+	}
+	return sessions, nil
+}
+
+func (u *authUsecase) RevokeSession(userID, sessionID string) error {
+	if _, err := uuid.Parse(sessionID); err != nil {
+		return ErrSessionNotFound
+	}
+
+	revoked, err := u.tokenRepo.RevokeSession(userID, sessionID)
+	if err != nil {
+		return fmt.Errorf("session revocation failed: %w", err)
+	}
+	if !revoked {
+		return ErrSessionNotFound
+	}
+	return nil
+}
+
+func (u *authUsecase) RevokeAllSessions(userID string) error {
+	if err := u.tokenRepo.RevokeAllSessions(userID); err != nil {
+		return fmt.Errorf("session revocation failed: %w", err)
+	}
+	return nil
+}
+
+func (u *authUsecase) GetJWKS() *shared.JWKS {
+	return u.signer.JWKS()
+}
+
+func (u *authUsecase) revokeReusedSession(token *domain.RefreshToken) error {
+	log.Printf("refresh token reuse detected for user %s (session %s)", token.UserID, token.SessionID)
+	if _, err := u.tokenRepo.RevokeSession(token.UserID, token.SessionID); err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+	return ErrRefreshTokenReused
+}
+
+func (u *authUsecase) newRefreshToken(userID, sessionID string) (string, *domain.RefreshToken, error) {
+	token, err := shared.GenerateOpaqueToken()
+	if err != nil {
+		return "", nil, fmt.Errorf("refresh token generation failed: %w", err)
+	}
+
+	now := time.Now()
+	return token, &domain.RefreshToken{
+		TokenID:   uuid.New().String(),
+		UserID:    userID,
+		SessionID: sessionID,
+		TokenHash: shared.HashOpaqueToken(token),
+		ExpiresAt: now.Add(u.config.RefreshTokenTTL),
+		CreatedAt: now,
+	}, nil
+}
+
+func (u *authUsecase) tokensFor(userID, sessionID, refreshToken string) (*AuthTokens, error) {
+	accessToken, err := u.signer.GenerateToken(userID, sessionID, u.config.AccessTokenTTL)
+	if err != nil {
+		return nil, fmt.Errorf("token generation failed: %w", err)
+	}
+
+	return &AuthTokens{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int(u.config.AccessTokenTTL.Seconds()),
+	}, nil
+}
@@ -0,0 +1,150 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/k-kanke/ashiato-backend/pkg/domain"
+	"github.com/k-kanke/ashiato-backend/pkg/repository"
+	"github.com/k-kanke/ashiato-backend/pkg/shared"
+)
+
+type CommentUsecase interface {
+	// ピンにコメントを投稿する
+	PostComment(userID, pinID, content string) (*domain.Comment, error)
+
+	// ピンのコメントをスレッド順に取得する（次ページのカーソルも返す）
+	GetComments(userID, pinID, cursor string, limit int) ([]domain.Comment, string, error)
+
+	// コメントを削除する（投稿者またはピンの所有者のみ）
+	DeleteComment(userID, pinID, commentID string) error
+}
+
+type commentUsecase struct {
+	pinRepo        repository.PinRepository
+	notificationUc NotificationUsecase
+}
+
+func NewCommentUsecase(pinRepo repository.PinRepository, nu NotificationUsecase) CommentUsecase {
+	return &commentUsecase{pinRepo: pinRepo, notificationUc: nu}
+}
+
+var (
+	ErrCommentNotFound   = errors.New("comment not found")
+	ErrCommentForbidden  = errors.New("not allowed to delete this comment")
+	ErrEmptyComment      = errors.New("comment content is empty")
+	ErrInvalidPageCursor = errors.New("invalid page cursor")
+)
+
+// PostComment はピンの閲覧権限を確認したうえでコメントを作成する
+func (u *commentUsecase) PostComment(userID, pinID, content string) (*domain.Comment, error) {
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return nil, ErrEmptyComment
+	}
+
+	// 見えないピン（他人のフレンド限定ピンなど）にはコメントさせない
+	pin, err := u.findVisiblePin(userID, pinID)
+	if err != nil {
+		return nil, err
+	}
+
+	comment := &domain.Comment{
+		CommentID:   uuid.New().String(),
+		PinID:       pinID,
+		UserID:      userID,
+		ContentText: content,
+		CreatedAt:   time.Now(),
+	}
+
+	if err := u.pinRepo.CreateComment(comment); err != nil {
+		return nil, fmt.Errorf("comment creation failed: %w", err)
+	}
+
+	// ピンの所有者に通知（通知の失敗でコメント自体は失敗させない）
+	if err := u.notificationUc.NotifyComment(userID, pin); err != nil {
+		log.Printf("failed to notify comment: %v", err)
+	}
+
+	return comment, nil
+}
+
+// GetComments はカーソルページネーションでコメントを取得する
+func (u *commentUsecase) GetComments(userID, pinID, cursor string, limit int) ([]domain.Comment, string, error) {
+	if _, err := u.findVisiblePin(userID, pinID); err != nil {
+		return nil, "", err
+	}
+
+	after, err := shared.DecodeCursor(cursor)
+	if err != nil {
+		return nil, "", ErrInvalidPageCursor
+	}
+
+	limit = normalizePageSize(limit)
+
+	// 1件多く取得し、次ページの有無を判定する
+	comments, err := u.pinRepo.GetComments(pinID, after, limit+1)
+	if err != nil {
+		return nil, "", fmt.Errorf("usecase failed to get comments: %w", err)
+	}
+
+	nextCursor := ""
+	if len(comments) > limit {
+		comments = comments[:limit]
+		last := comments[len(comments)-1]
+		nextCursor = shared.EncodeCursor(last.CreatedAt, last.CommentID)
+	}
+
+	return comments, nextCursor, nil
+}
+
+// DeleteComment はコメント投稿者またはピン所有者による削除を行う
+func (u *commentUsecase) DeleteComment(userID, pinID, commentID string) error {
+	pin, err := u.findVisiblePin(userID, pinID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := uuid.Parse(commentID); err != nil {
+		return ErrCommentNotFound
+	}
+
+	comment, err := u.pinRepo.FindCommentByID(commentID)
+	if err != nil {
+		return fmt.Errorf("failed to find comment: %w", err)
+	}
+	if comment == nil || comment.PinID != pinID {
+		return ErrCommentNotFound
+	}
+
+	if comment.UserID != userID && pin.UserID != userID {
+		return ErrCommentForbidden
+	}
+
+	if err := u.pinRepo.DeleteComment(commentID); err != nil {
+		return fmt.Errorf("failed to delete comment: %w", err)
+	}
+
+	return nil
+}
+
+func (u *commentUsecase) findVisiblePin(userID, pinID string) (*domain.Pin, error) {
+	if _, err := uuid.Parse(pinID); err != nil {
+		return nil, ErrPinNotFound
+	}
+
+	pin, err := u.pinRepo.FindVisiblePin(userID, pinID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find pin: %w", err)
+	}
+	// 存在しないピンと閲覧権限のないピンは区別しない
+	if pin == nil {
+		return nil, ErrPinNotFound
+	}
+
+	return pin, nil
+}
@@ -3,8 +3,12 @@ package usecase
 import (
 	"errors"
 	"fmt"
+	"log"
 
+	"github.com/google/uuid"
+	"github.com/k-kanke/ashiato-backend/pkg/domain"
 	"github.com/k-kanke/ashiato-backend/pkg/repository"
+	"github.com/k-kanke/ashiato-backend/pkg/shared"
 )
 
 type FriendUsecase interface {
@@ -14,23 +18,135 @@ type FriendUsecase interface {
 	// フレンド申請を承認する
 	AcceptFriendship(accepterID, targetID string) error
 
-	// フレンド一覧を取得する
-	GetFriendsList(userID string) ([]string, error)
+	// フレンド申請を拒否する
+	RejectFriendship(rejecterID, targetID string) error
+
+	// 自分が送ったフレンド申請を取り消す
+	CancelFriendship(requesterID, targetID string) error
+
+	// フレンドを解除する
+	Unfriend(userID, targetID string) error
+
+	// ユーザーをブロックする
+	BlockUser(blockerID, targetID string) error
+
+	// ブロックを解除する
+	UnblockUser(blockerID, targetID string) error
+
+	// フレンド一覧を取得する（次ページのカーソルも返す）
+	GetFriendsList(userID, cursor string, limit int) ([]domain.FriendSummary, string, error)
+
+	// 自分宛てのフレンド申請一覧を取得する
+	GetIncomingRequests(userID, cursor string, limit int) ([]domain.FriendSummary, string, error)
+
+	// 自分が送ったフレンド申請一覧を取得する
+	GetOutgoingRequests(userID, cursor string, limit int) ([]domain.FriendSummary, string, error)
+
+	// 共通のフレンドと近くの足あとを元にフレンド候補を取得する
+	GetFriendSuggestions(userID string, limit int) ([]domain.FriendSuggestion, error)
 }
 
 type friendUsecase struct {
-	friendRepo repository.FriendRepository
+	friendRepo     repository.FriendRepository
+	notificationUc NotificationUsecase
 }
 
-func NewFriendUsecase(fr repository.FriendRepository) FriendUsecase {
-	return &friendUsecase{friendRepo: fr}
+func NewFriendUsecase(fr repository.FriendRepository, nu NotificationUsecase) FriendUsecase {
+	return &friendUsecase{friendRepo: fr, notificationUc: nu}
+}
+
+var (
+	ErrInvalidFriendTarget         = errors.New("invalid target user")
+	ErrAlreadyFriends              = errors.New("already friends")
+	ErrFriendRequestPending        = errors.New("request already pending")
+	ErrFriendshipNotFound          = errors.New("friendship not found")
+	ErrInvalidFriendshipTransition = errors.New("invalid friendship status transition")
+)
+
+// friendshipAction はフレンド関係に対する操作
+type friendshipAction string
+
+const (
+	friendshipActionRequest  friendshipAction = "request"
+	friendshipActionAccept   friendshipAction = "accept"
+	friendshipActionReject   friendshipAction = "reject"
+	friendshipActionCancel   friendshipAction = "cancel"
+	friendshipActionUnfriend friendshipAction = "unfriend"
+	friendshipActionBlock    friendshipAction = "block"
+	friendshipActionUnblock  friendshipAction = "unblock"
+)
+
+// nextFriendshipStatus はフレンド関係の状態遷移を定義する
+// 戻り値の next が空文字の場合は関係そのものを削除することを表す
+//
+//	(なし)/rejected/cancelled --request(申請者)--> pending
+//	pending --accept/reject(申請の受信者)--> accepted/rejected
+//	pending --cancel(申請者)--> cancelled
+//	accepted --unfriend(どちらか)--> (なし)
+//	(任意) --block(どちらか)--> blocked
+//	blocked --unblock(ブロックした本人)--> (なし)
+func nextFriendshipStatus(current *domain.Friendship, actorID string, action friendshipAction) (string, error) {
+	status := ""
+	if current != nil {
+		status = current.Status
+	}
+	// 直前に状態を変更したユーザーが自分自身かどうか
+	isActor := current != nil && current.ActionUserID == actorID
+
+	switch action {
+	case friendshipActionRequest:
+		switch status {
+		case "", domain.FriendshipStatusRejected, domain.FriendshipStatusCancelled:
+			return domain.FriendshipStatusPending, nil
+		case domain.FriendshipStatusAccepted:
+			return "", ErrAlreadyFriends
+		case domain.FriendshipStatusPending:
+			return "", ErrFriendRequestPending
+		}
+
+	case friendshipActionAccept, friendshipActionReject:
+		if status != domain.FriendshipStatusPending {
+			return "", ErrFriendshipNotFound
+		}
+		// 申請者自身は承認・拒否できない
+		if isActor {
+			return "", fmt.Errorf("%w: requester cannot %s own request", ErrInvalidFriendshipTransition, action)
+		}
+		if action == friendshipActionAccept {
+			return domain.FriendshipStatusAccepted, nil
+		}
+		return domain.FriendshipStatusRejected, nil
+
+	case friendshipActionCancel:
+		if status != domain.FriendshipStatusPending || !isActor {
+			return "", ErrFriendshipNotFound
+		}
+		return domain.FriendshipStatusCancelled, nil
+
+	case friendshipActionUnfriend:
+		if status != domain.FriendshipStatusAccepted {
+			return "", ErrFriendshipNotFound
+		}
+		return "", nil
+
+	case friendshipActionBlock:
+		return domain.FriendshipStatusBlocked, nil
+
+	case friendshipActionUnblock:
+		if status != domain.FriendshipStatusBlocked || !isActor {
+			return "", ErrFriendshipNotFound
+		}
+		return "", nil
+	}
+
+	return "", fmt.Errorf("%w: cannot %s from %q", ErrInvalidFriendshipTransition, action, status)
 }
 
 // RequestFriendship はフレンド申請ロジックを実行する
 func (uc *friendUsecase) RequestFriendship(requesterID, targetID string) error {
-	// 1. 自己申請のチェック
-	if requesterID == targetID {
-		return errors.New("cannot request friendship to self")
+	// 1. 自己申請・不正なIDのチェック
+	if err := validateFriendTarget(requesterID, targetID); err != nil {
+		return err
 	}
 
 	// 2. 既存の関係をチェック
@@ -38,69 +154,227 @@ func (uc *friendUsecase) RequestFriendship(requesterID, targetID string) error {
 	if err != nil {
 		return fmt.Errorf("failed to check existing friendship: %w", err)
 	}
-	if friendship != nil {
-		if friendship.Status == "accepted" {
-			return errors.New("already friends")
+
+	// ブロック中の関係には申請できない（どちらがブロックしたかは明かさない）
+	if friendship != nil && friendship.Status == domain.FriendshipStatusBlocked {
+		return fmt.Errorf("%w: cannot request friendship", ErrInvalidFriendshipTransition)
+	}
+
+	next, err := nextFriendshipStatus(friendship, requesterID, friendshipActionRequest)
+	if err != nil {
+		return err
+	}
+
+	// 3. リポジトリで申請を作成 (status='pending')
+	if friendship == nil {
+		// userAID < userBID の順序をGoのロジックで保証する必要がある
+		userA, userB := requesterID, targetID
+		if requesterID > targetID {
+			userA, userB = targetID, requesterID
+		}
+
+		if err := uc.friendRepo.CreateFriendship(userA, userB, requesterID); err != nil {
+			return fmt.Errorf("failed to create friendship request: %w", err)
 		}
-		if friendship.Status == "pending" {
-			return errors.New("request already pending")
+	} else {
+		// 拒否・取り消し済みの関係から再申請する
+		if err := uc.friendRepo.UpdateFriendshipStatus(requesterID, targetID, next, requesterID); err != nil {
+			return fmt.Errorf("failed to create friendship request: %w", err)
 		}
 	}
-	// ... DBエラー処理
 
-	// 3. リポジトリで新規申請を作成 (status='pending')
-	// userAID < userBID の順序をGoのロジックで保証する必要がある
-	userA, userB := requesterID, targetID
-	if requesterID > targetID {
-		userA, userB = targetID, requesterID
+	// 4. TargetID に通知を生成（通知の失敗で申請自体は失敗させない）
+	if err := uc.notificationUc.NotifyFriendRequest(requesterID, targetID); err != nil {
+		log.Printf("failed to notify friend request: %v", err)
 	}
 
-	if err := uc.friendRepo.CreateFriendship(userA, userB, requesterID); err != nil {
-		return fmt.Errorf("failed to create friendship request: %w", err)
+	return nil
+}
+
+func (uc *friendUsecase) AcceptFriendship(accepterID, targetID string) error {
+	friendship, err := uc.transition(accepterID, targetID, friendshipActionAccept)
+	if err != nil {
+		return err
 	}
 
-	// 4. 通知ロジック（後で実装）: TargetID に通知を生成
-	// ...
+	// 申請者に承認通知を生成
+	if err := uc.notificationUc.NotifyFriendAccepted(accepterID, friendship.ActionUserID); err != nil {
+		log.Printf("failed to notify friend acceptance: %v", err)
+	}
 
 	return nil
 }
 
-func (uc *friendUsecase) AcceptFriendship(accepterID, targetID string) error {
-	friendship, err := uc.friendRepo.FindFriendshipStatus(accepterID, targetID)
+func (uc *friendUsecase) RejectFriendship(rejecterID, targetID string) error {
+	_, err := uc.transition(rejecterID, targetID, friendshipActionReject)
+	return err
+}
+
+func (uc *friendUsecase) CancelFriendship(requesterID, targetID string) error {
+	_, err := uc.transition(requesterID, targetID, friendshipActionCancel)
+	return err
+}
+
+func (uc *friendUsecase) Unfriend(userID, targetID string) error {
+	_, err := uc.transition(userID, targetID, friendshipActionUnfriend)
+	return err
+}
+
+func (uc *friendUsecase) BlockUser(blockerID, targetID string) error {
+	if err := validateFriendTarget(blockerID, targetID); err != nil {
+		return err
+	}
+
+	friendship, err := uc.friendRepo.FindFriendshipStatus(blockerID, targetID)
 	if err != nil {
 		return fmt.Errorf("failed to check friendship status: %w", err)
 	}
-	if friendship == nil {
-		return errors.New("friendship request not found")
+
+	// 既にどちらかがブロックしている場合は何もしない
+	// （相手からブロックされていることを明かさないため、エラーにはしない）
+	if friendship != nil && friendship.Status == domain.FriendshipStatusBlocked {
+		return nil
+	}
+
+	next, err := nextFriendshipStatus(friendship, blockerID, friendshipActionBlock)
+	if err != nil {
+		return err
 	}
 
-	if friendship.Status != "pending" {
-		return errors.New("no pending request exists")
+	// 関係がなくてもブロックできるよう、作成または上書きする
+	if err := uc.friendRepo.SaveFriendship(blockerID, targetID, next, blockerID); err != nil {
+		return fmt.Errorf("failed to block user: %w", err)
 	}
 
-	// 承認するのは、申請の対象者（つまり、action_user_id ではない方）でなければならないというチェックも必要。
-	// ...
+	return nil
+}
+
+func (uc *friendUsecase) UnblockUser(blockerID, targetID string) error {
+	_, err := uc.transition(blockerID, targetID, friendshipActionUnblock)
+	return err
+}
 
-	// 3. リポジトリでステータスを 'accepted' に更新
-	if err := uc.friendRepo.UpdateFriendshipStatus(
-		friendship.UserAID,
-		friendship.UserBID,
-		"accepted",
-		accepterID,
-	); err != nil {
-		return fmt.Errorf("failed to accept friendship: %w", err)
+// transition は既存の関係に状態遷移を適用し、遷移前の関係を返す
+func (uc *friendUsecase) transition(actorID, targetID string, action friendshipAction) (*domain.Friendship, error) {
+	if err := validateFriendTarget(actorID, targetID); err != nil {
+		return nil, err
 	}
 
-	// 4. 通知ロジック（後で実装）: 申請者に承認通知を生成
-	// ...
+	friendship, err := uc.friendRepo.FindFriendshipStatus(actorID, targetID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check friendship status: %w", err)
+	}
+	if friendship == nil {
+		return nil, ErrFriendshipNotFound
+	}
+
+	next, err := nextFriendshipStatus(friendship, actorID, action)
+	if err != nil {
+		return nil, err
+	}
 
+	if next == "" {
+		err = uc.friendRepo.DeleteFriendship(friendship.UserAID, friendship.UserBID)
+	} else {
+		err = uc.friendRepo.UpdateFriendshipStatus(friendship.UserAID, friendship.UserBID, next, actorID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to %s friendship: %w", action, err)
+	}
+
+	return friendship, nil
+}
+
+func validateFriendTarget(userID, targetID string) error {
+	if userID == targetID {
+		return fmt.Errorf("%w: cannot target self", ErrInvalidFriendTarget)
+	}
+	if _, err := uuid.Parse(targetID); err != nil {
+		return fmt.Errorf("%w: malformed user id", ErrInvalidFriendTarget)
+	}
 	return nil
 }
 
-func (uc *friendUsecase) GetFriendsList(userID string) ([]string, error) {
-	friendIDs, err := uc.friendRepo.GetFriendsList(userID)
+func (uc *friendUsecase) GetFriendsList(userID, cursor string, limit int) ([]domain.FriendSummary, string, error) {
+	return uc.listFriendSummaries(userID, cursor, limit, uc.friendRepo.GetFriendsList)
+}
+
+func (uc *friendUsecase) GetIncomingRequests(userID, cursor string, limit int) ([]domain.FriendSummary, string, error) {
+	return uc.listFriendSummaries(userID, cursor, limit, uc.friendRepo.GetIncomingRequests)
+}
+
+func (uc *friendUsecase) GetOutgoingRequests(userID, cursor string, limit int) ([]domain.FriendSummary, string, error) {
+	return uc.listFriendSummaries(userID, cursor, limit, uc.friendRepo.GetOutgoingRequests)
+}
+
+// listFriendSummaries はフレンド関連の一覧取得に共通のページネーションを適用する
+func (uc *friendUsecase) listFriendSummaries(
+	userID, cursor string,
+	limit int,
+	fetch func(userID string, cursor *shared.Cursor, limit int) ([]domain.FriendSummary, error),
+) ([]domain.FriendSummary, string, error) {
+	after, err := shared.DecodeCursor(cursor)
+	if err != nil {
+		return nil, "", ErrInvalidPageCursor
+	}
+
+	limit = normalizePageSize(limit)
+
+	// 1件多く取得し、次ページの有無を判定する
+	summaries, err := fetch(userID, after, limit+1)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get friend list: %w", err)
+	}
+
+	nextCursor := ""
+	if len(summaries) > limit {
+		summaries = summaries[:limit]
+		last := summaries[len(summaries)-1]
+		nextCursor = shared.EncodeCursor(last.Since, last.UserID)
+	}
+
+	return summaries, nextCursor, nil
+}
+
+// フレンド候補のスコアリング設定
+var suggestionScoring = domain.SuggestionScoring{
+	RecentPinCount:     20,
+	RadiusMeters:       500,
+	MutualFriendWeight: 3,
+	NearbyPinWeight:    1,
+	NearbyPinCountCap:  10,
+}
+
+func (uc *friendUsecase) GetFriendSuggestions(userID string, limit int) ([]domain.FriendSuggestion, error) {
+	limit = normalizePageSize(limit)
+
+	// スコアの計算と並び替えは、候補を絞り込む前に行う必要があるため SQL で行う
+	candidates, err := uc.friendRepo.GetSuggestionCandidates(userID, suggestionScoring, limit)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get friend list: %w", err)
+		return nil, fmt.Errorf("failed to get friend suggestions: %w", err)
+	}
+
+	for i := range candidates {
+		candidates[i].Reasons = suggestionReasons(candidates[i])
+	}
+
+	return candidates, nil
+}
+
+// suggestionReasons は候補に挙げた理由をクライアント表示用の文に変換する
+func suggestionReasons(s domain.FriendSuggestion) []string {
+	reasons := make([]string, 0, 2)
+	switch {
+	case s.MutualFriendCount == 1:
+		reasons = append(reasons, "1 mutual friend")
+	case s.MutualFriendCount > 1:
+		reasons = append(reasons, fmt.Sprintf("%d mutual friends", s.MutualFriendCount))
+	}
+	switch {
+	case s.NearbyPinCount == 1:
+		reasons = append(reasons, "posts near your recent footprints")
+	case s.NearbyPinCount > 1:
+		reasons = append(reasons, fmt.Sprintf("%d posts near your recent footprints", s.NearbyPinCount))
 	}
-	return friendIDs, nil
+	return reasons
 }
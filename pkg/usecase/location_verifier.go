@@ -0,0 +1,246 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/k-kanke/ashiato-backend/pkg/repository"
+	"github.com/k-kanke/ashiato-backend/pkg/shared"
+)
+
+// LocationClaim はピン投稿時にクライアントが申告した現在地と、その根拠となる情報
+type LocationClaim struct {
+	UserID         string
+	Latitude       float64
+	Longitude      float64
+	AccuracyMeters float64   // 端末が報告した測位精度（0 は未申告）
+	IsMocked       bool      // 端末の擬似ロケーション機能が有効かどうか
+	LocationToken  string    // 信頼済みクライアントSDKが発行した署名付き位置トークン
+	TravelMode     string    // 申告された移動手段（空の場合は設定のデフォルト）
+	ClaimedAt      time.Time // サーバーが投稿を受け付けた時刻
+}
+
+// LocationVerifier は申告された位置が信頼できるかを検証する
+// 拒否する場合は *LocationRejection を返す
+type LocationVerifier interface {
+	Verify(claim *LocationClaim) error
+}
+
+// 位置情報を拒否した理由コード
+const (
+	LocationReasonMockLocation     = "mock_location"
+	LocationReasonLowAccuracy      = "low_accuracy"
+	LocationReasonImplausibleSpeed = "implausible_speed"
+	LocationReasonInvalidToken     = "invalid_location_token"
+	LocationReasonTokenRequired    = "location_token_required"
+)
+
+var ErrLocationNotVerified = errors.New("location not verified")
+
+// LocationRejection は位置検証で投稿を拒否した理由
+type LocationRejection struct {
+	Reason  string
+	Message string
+	RetryAt time.Time // 再投稿が可能になる時刻（ゼロ値の場合は時間経過で解消しない）
+}
+
+func (e *LocationRejection) Error() string {
+	return fmt.Sprintf("%s: %s", ErrLocationNotVerified.Error(), e.Message)
+}
+
+func (e *LocationRejection) Is(target error) bool {
+	return target == ErrLocationNotVerified
+}
+
+func rejectLocation(reason, format string, args ...interface{}) *LocationRejection {
+	return &LocationRejection{Reason: reason, Message: fmt.Sprintf(format, args...)}
+}
+
+// locationVerifierChain は登録順に検証し、最初の拒否を返す
+type locationVerifierChain []LocationVerifier
+
+func NewLocationVerifierChain(verifiers ...LocationVerifier) LocationVerifier {
+	return locationVerifierChain(verifiers)
+}
+
+func (c locationVerifierChain) Verify(claim *LocationClaim) error {
+	for _, v := range c {
+		if err := v.Verify(claim); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mockLocationVerifier は擬似ロケーションが有効な端末からの投稿を拒否する
+type mockLocationVerifier struct{}
+
+func NewMockLocationVerifier() LocationVerifier {
+	return mockLocationVerifier{}
+}
+
+func (mockLocationVerifier) Verify(claim *LocationClaim) error {
+	if claim.IsMocked {
+		return rejectLocation(LocationReasonMockLocation, "mock location is enabled on the device")
+	}
+	return nil
+}
+
+// accuracyVerifier は測位精度が粗すぎる投稿を拒否する
+type accuracyVerifier struct {
+	maxAccuracyMeters float64
+}
+
+func NewAccuracyVerifier(maxAccuracyMeters float64) LocationVerifier {
+	return &accuracyVerifier{maxAccuracyMeters: maxAccuracyMeters}
+}
+
+func (v *accuracyVerifier) Verify(claim *LocationClaim) error {
+	if claim.AccuracyMeters < 0 {
+		return rejectLocation(LocationReasonLowAccuracy, "accuracy must not be negative")
+	}
+	if claim.AccuracyMeters > v.maxAccuracyMeters {
+		return rejectLocation(LocationReasonLowAccuracy,
+			"accuracy %.0fm exceeds %.0fm", claim.AccuracyMeters, v.maxAccuracyMeters)
+	}
+	return nil
+}
+
+// 移動手段
+const (
+	TravelModeWalk = "walk"
+	TravelModeCar  = "car"
+	TravelModeRail = "rail"
+	TravelModeAir  = "air"
+)
+
+var ErrInvalidTravelMode = errors.New("invalid travel mode")
+
+// TravelPlausibilityConfig は移動速度による位置検証のしきい値
+type TravelPlausibilityConfig struct {
+	Window           int                // 速度を検証する直近ピンの件数
+	MinCheckedMeters float64            // GPSの揺らぎで誤検知しないよう、これ未満の移動は検証しない
+	MaxSpeedKmh      map[string]float64 // 移動手段ごとの最高速度
+	DefaultMode      string             // 移動手段が申告されなかった場合に適用する
+}
+
+func DefaultTravelPlausibilityConfig() TravelPlausibilityConfig {
+	return TravelPlausibilityConfig{
+		Window:           5,
+		MinCheckedMeters: 1000,
+		MaxSpeedKmh: map[string]float64{
+			TravelModeWalk: 30,
+			TravelModeCar:  200,
+			TravelModeRail: 350,
+			TravelModeAir:  1100,
+		},
+		DefaultMode: TravelModeRail,
+	}
+}
+
+// travelVerifier は直近N件のピンそれぞれからの移動速度が、申告された移動手段で可能な範囲かを検証する
+type travelVerifier struct {
+	pinRepo repository.PinRepository
+	config  TravelPlausibilityConfig
+}
+
+func NewTravelVerifier(pinRepo repository.PinRepository, config TravelPlausibilityConfig) LocationVerifier {
+	return &travelVerifier{pinRepo: pinRepo, config: config}
+}
+
+func (v *travelVerifier) Verify(claim *LocationClaim) error {
+	mode := claim.TravelMode
+	if mode == "" {
+		mode = v.config.DefaultMode
+	}
+	maxSpeedKmh, ok := v.config.MaxSpeedKmh[mode]
+	if !ok || maxSpeedKmh <= 0 {
+		return fmt.Errorf("%w: %q", ErrInvalidTravelMode, mode)
+	}
+	maxSpeedMps := maxSpeedKmh * 1000 / 3600
+
+	recentPins, err := v.pinRepo.GetRecentPins(claim.UserID, v.config.Window)
+	if err != nil {
+		return fmt.Errorf("location validation failed: %w", err)
+	}
+
+	var rejection *LocationRejection
+	for _, pin := range recentPins {
+		distance := haversineMeters(pin.Latitude, pin.Longitude, claim.Latitude, claim.Longitude)
+		if distance < v.config.MinCheckedMeters {
+			continue
+		}
+
+		// 最高速度で移動した場合に到着できる最も早い時刻
+		earliestArrival := pin.CreatedAt.Add(time.Duration(distance / maxSpeedMps * float64(time.Second)))
+		if !earliestArrival.After(claim.ClaimedAt) {
+			continue
+		}
+
+		if rejection == nil {
+			elapsed := math.Max(claim.ClaimedAt.Sub(pin.CreatedAt).Seconds(), 1)
+			rejection = rejectLocation(LocationReasonImplausibleSpeed,
+				"implied speed %.0fkm/h since pin %s exceeds %.0fkm/h for %s",
+				distance/elapsed*3.6, pin.PinID, maxSpeedKmh, mode)
+		}
+		// 全ての直近ピンからの移動が妥当になる時刻を再投稿可能時刻とする
+		if earliestArrival.After(rejection.RetryAt) {
+			rejection.RetryAt = earliestArrival
+		}
+	}
+
+	if rejection != nil {
+		return rejection
+	}
+	return nil
+}
+
+// locationTokenVerifier は信頼済みクライアントSDKの署名付き位置トークンを検証する
+type locationTokenVerifier struct {
+	secret          string
+	required        bool
+	maxAge          time.Duration
+	maxOffsetMeters float64 // トークンの位置と申告位置の許容差
+}
+
+// NewLocationTokenVerifier は required が false の場合、トークンが付いている投稿のみ検証する
+func NewLocationTokenVerifier(secret string, required bool) LocationVerifier {
+	return &locationTokenVerifier{
+		secret:          secret,
+		required:        required,
+		maxAge:          5 * time.Minute,
+		maxOffsetMeters: 100,
+	}
+}
+
+func (v *locationTokenVerifier) Verify(claim *LocationClaim) error {
+	if claim.LocationToken == "" {
+		if v.required {
+			return rejectLocation(LocationReasonTokenRequired, "a signed location token is required")
+		}
+		return nil
+	}
+
+	token, err := shared.ParseLocationToken(claim.LocationToken, v.secret)
+	if err != nil {
+		return rejectLocation(LocationReasonInvalidToken, "signature verification failed")
+	}
+	if token.UserID != claim.UserID {
+		return rejectLocation(LocationReasonInvalidToken, "token was issued to another user")
+	}
+
+	age := claim.ClaimedAt.Sub(token.IssuedAtTime())
+	if age < -time.Minute || age > v.maxAge {
+		return rejectLocation(LocationReasonInvalidToken, "token is expired")
+	}
+
+	offset := haversineMeters(token.Latitude, token.Longitude, claim.Latitude, claim.Longitude)
+	if offset > v.maxOffsetMeters {
+		return rejectLocation(LocationReasonInvalidToken,
+			"token location is %.0fm away from the claimed location", offset)
+	}
+
+	return nil
+}
@@ -0,0 +1,191 @@
+package usecase
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/k-kanke/ashiato-backend/pkg/domain"
+	"github.com/k-kanke/ashiato-backend/pkg/repository"
+	"github.com/k-kanke/ashiato-backend/pkg/shared/media"
+)
+
+type MediaUsecase interface {
+	// 画像をアップロードし、EXIF除去・サムネイル生成を行ってメディアIDを発行する
+	UploadMedia(userID string, r io.Reader) (*domain.Media, error)
+
+	// 閲覧者が参照できるメディアのファイルを開く（variant は original または thumbnail）
+	OpenMedia(viewerID, mediaID, variant string) (io.ReadCloser, string, error)
+}
+
+type mediaUsecase struct {
+	mediaRepo repository.MediaRepository
+	pinRepo   repository.PinRepository
+	storage   repository.MediaStorage
+	slots     chan struct{} // 同時に展開する画像数を制限するセマフォ
+}
+
+func NewMediaUsecase(
+	mediaRepo repository.MediaRepository,
+	pinRepo repository.PinRepository,
+	storage repository.MediaStorage,
+) MediaUsecase {
+	return &mediaUsecase{
+		mediaRepo: mediaRepo,
+		pinRepo:   pinRepo,
+		storage:   storage,
+		slots:     make(chan struct{}, maxConcurrentMediaProcessing),
+	}
+}
+
+var (
+	ErrUnsupportedMediaType = errors.New("unsupported media type")
+	ErrMediaTooLarge        = errors.New("media file too large")
+	ErrInvalidMedia         = errors.New("invalid media file")
+	ErrMediaNotFound        = errors.New("media not found")
+)
+
+// メディアの配信形式
+const (
+	MediaVariantOriginal  = "original"
+	MediaVariantThumbnail = "thumbnail"
+)
+
+const (
+	MaxMediaBytes      = 10 << 20 // 10MB
+	mediaThumbnailSize = 320      // サムネイルの長辺 (px)
+
+	// 展開後の画像はアップロードサイズよりはるかに大きくなるため、同時に処理する数を制限してメモリを抑える
+	maxConcurrentMediaProcessing = 4
+)
+
+// UploadMedia は画像を検証・再エンコードして保存する
+func (u *mediaUsecase) UploadMedia(userID string, r io.Reader) (*domain.Media, error) {
+	data, err := io.ReadAll(io.LimitReader(r, MaxMediaBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read media: %w", err)
+	}
+	if len(data) > MaxMediaBytes {
+		return nil, fmt.Errorf("%w: max %d bytes", ErrMediaTooLarge, MaxMediaBytes)
+	}
+
+	sanitized, thumbnail, err := u.processImage(data)
+	if err != nil {
+		return nil, err
+	}
+
+	mediaID := uuid.New().String()
+	item := &domain.Media{
+		MediaID:      mediaID,
+		UserID:       userID,
+		ContentType:  sanitized.ContentType,
+		Width:        sanitized.Width,
+		Height:       sanitized.Height,
+		SizeBytes:    int64(len(sanitized.Data)),
+		StorageKey:   mediaID + "/original" + sanitized.Extension(),
+		ThumbnailKey: mediaID + "/thumbnail" + thumbnail.Extension(),
+		CreatedAt:    time.Now(),
+	}
+
+	if err := u.storage.Save(item.StorageKey, bytes.NewReader(sanitized.Data)); err != nil {
+		return nil, fmt.Errorf("failed to store media: %w", err)
+	}
+	if err := u.storage.Save(item.ThumbnailKey, bytes.NewReader(thumbnail.Data)); err != nil {
+		u.cleanup(item.StorageKey)
+		return nil, fmt.Errorf("failed to store thumbnail: %w", err)
+	}
+
+	if err := u.mediaRepo.CreateMedia(item); err != nil {
+		u.cleanup(item.StorageKey, item.ThumbnailKey)
+		return nil, fmt.Errorf("media creation failed: %w", err)
+	}
+
+	setMediaURLs(item)
+	return item, nil
+}
+
+// OpenMedia はメディアのファイルと Content-Type を返す。
+// アップロードした本人か、メディアを添付したピンを参照できるユーザーのみが取得できる
+func (u *mediaUsecase) OpenMedia(viewerID, mediaID, variant string) (io.ReadCloser, string, error) {
+	if _, err := uuid.Parse(mediaID); err != nil {
+		return nil, "", ErrMediaNotFound
+	}
+
+	item, err := u.mediaRepo.FindMediaByID(mediaID)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to find media: %w", err)
+	}
+	if item == nil {
+		return nil, "", ErrMediaNotFound
+	}
+
+	if item.UserID != viewerID {
+		// 権限がない場合もメディアの存在を知られないよう NotFound として扱う
+		visible, err := u.pinRepo.HasVisiblePinWithMedia(viewerID, mediaURL(item.MediaID))
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to check media visibility: %w", err)
+		}
+		if !visible {
+			return nil, "", ErrMediaNotFound
+		}
+	}
+
+	key := item.StorageKey
+	if variant == MediaVariantThumbnail {
+		key = item.ThumbnailKey
+	}
+
+	file, err := u.storage.Open(key)
+	if err != nil {
+		if errors.Is(err, repository.ErrMediaObjectNotFound) {
+			return nil, "", ErrMediaNotFound
+		}
+		return nil, "", fmt.Errorf("failed to open media: %w", err)
+	}
+	return file, item.ContentType, nil
+}
+
+// processImage は再エンコードで EXIF（GPS座標を含む）を取り除き、サムネイルを生成する
+func (u *mediaUsecase) processImage(data []byte) (*media.Image, *media.Image, error) {
+	u.slots <- struct{}{}
+	defer func() { <-u.slots }()
+
+	sanitized, decoded, err := media.Sanitize(data)
+	if err != nil {
+		if errors.Is(err, media.ErrUnsupportedType) {
+			return nil, nil, fmt.Errorf("%w: only JPEG and PNG are allowed", ErrUnsupportedMediaType)
+		}
+		if errors.Is(err, media.ErrInvalidImage) {
+			return nil, nil, fmt.Errorf("%w: %v", ErrInvalidMedia, err)
+		}
+		return nil, nil, fmt.Errorf("media processing failed: %w", err)
+	}
+
+	thumbnail, err := media.Thumbnail(decoded, sanitized.ContentType, mediaThumbnailSize)
+	if err != nil {
+		return nil, nil, fmt.Errorf("thumbnail generation failed: %w", err)
+	}
+	return sanitized, thumbnail, nil
+}
+
+func (u *mediaUsecase) cleanup(keys ...string) {
+	for _, key := range keys {
+		if err := u.storage.Delete(key); err != nil {
+			log.Printf("failed to clean up media %s: %v", key, err)
+		}
+	}
+}
+
+// mediaURL はサーバーが配信するメディアのパス（ピンの media_url にもこの形式で保存する）
+func mediaURL(mediaID string) string {
+	return "/v1/media/" + mediaID
+}
+
+func setMediaURLs(item *domain.Media) {
+	item.URL = mediaURL(item.MediaID)
+	item.ThumbnailURL = mediaURL(item.MediaID) + "/" + MediaVariantThumbnail
+}
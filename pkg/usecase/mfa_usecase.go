@@ -0,0 +1,367 @@
+package usecase
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/k-kanke/ashiato-backend/pkg/domain"
+	"github.com/k-kanke/ashiato-backend/pkg/repository"
+	"github.com/k-kanke/ashiato-backend/pkg/shared"
+)
+
+type MFAUsecase interface {
+	// TOTP の登録を開始し、認証アプリに登録する秘密鍵と otpauth URI を返す
+	BeginTOTPEnrollment(userID string) (*TOTPEnrollment, error)
+
+	// 認証アプリのコードで登録を確定し、リカバリーコードを返す
+	ConfirmTOTPEnrollment(userID, code string) ([]string, error)
+
+	// リカバリーコードを再発行する（以前のコードは使えなくなる）
+	RegenerateRecoveryCodes(userID, code string) ([]string, error)
+
+	// 二要素認証を無効にする
+	DisableMFA(userID, code string) error
+
+	// 二要素認証が有効かどうか
+	IsMFAEnabled(userID string) (bool, error)
+
+	// パスワード認証に成功したユーザーに、二要素認証待ちのチャレンジを発行する
+	StartChallenge(userID string) (*MFAChallenge, error)
+
+	// チャレンジと TOTP またはリカバリーコードでログインを完了する
+	CompleteChallenge(mfaToken, code string, client ClientInfo) (*AuthTokens, error)
+}
+
+// TOTPEnrollment は認証アプリへの登録に使う情報
+type TOTPEnrollment struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+// MFAChallenge は二要素認証を待っているログイン
+type MFAChallenge struct {
+	MFAToken  string `json:"mfa_token"`
+	ExpiresIn int    `json:"expires_in"`
+}
+
+type mfaUsecase struct {
+	mfaRepo   repository.MFARepository
+	userRepo  repository.UserRepository
+	tokenRepo repository.TokenRepository
+	authUc    AuthUsecase
+	secretBox *shared.SecretBox
+}
+
+// NewMFAUsecase は secretBox が nil の場合、TOTP の登録・検証で ErrMFAUnavailable を返す
+func NewMFAUsecase(
+	mfaRepo repository.MFARepository,
+	userRepo repository.UserRepository,
+	tokenRepo repository.TokenRepository,
+	authUc AuthUsecase,
+	secretBox *shared.SecretBox,
+) MFAUsecase {
+	return &mfaUsecase{
+		mfaRepo:   mfaRepo,
+		userRepo:  userRepo,
+		tokenRepo: tokenRepo,
+		authUc:    authUc,
+		secretBox: secretBox,
+	}
+}
+
+var (
+	ErrMFAAlreadyEnabled   = errors.New("two-factor authentication is already enabled")
+	ErrMFANotEnabled       = errors.New("two-factor authentication is not enabled")
+	ErrMFANotEnrolled      = errors.New("two-factor authentication enrollment has not been started")
+	ErrInvalidMFACode      = errors.New("invalid authentication code")
+	ErrInvalidMFAChallenge = errors.New("invalid or expired mfa token")
+	ErrMFALocked           = errors.New("too many failed authentication attempts, try again later")
+	ErrMFAUnavailable      = errors.New("totp two-factor authentication is not configured on this server")
+)
+
+const (
+	totpIssuer             = "Ashiato"
+	mfaChallengeTTL        = 5 * time.Minute
+	maxMFAChallengeAttempt = 5  // 6桁のコードを総当たりされないよう、失敗が続いたチャレンジは無効にする
+	maxMFAFailedAttempt    = 10 // チャレンジを発行し直しての総当たりも防ぐため、ユーザー単位でも失敗回数を数える
+	mfaLockoutDuration     = 15 * time.Minute
+	recoveryCodeCount      = 10
+	recoveryCodeLength     = 10
+)
+
+func (u *mfaUsecase) BeginTOTPEnrollment(userID string) (*TOTPEnrollment, error) {
+	if u.secretBox == nil {
+		return nil, ErrMFAUnavailable
+	}
+
+	user, _, err := u.userRepo.FindUserByID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve user: %w", err)
+	}
+
+	secret, err := shared.GenerateTOTPSecret()
+	if err != nil {
+		return nil, fmt.Errorf("totp secret generation failed: %w", err)
+	}
+	encrypted, err := u.secretBox.Seal(secret, userID)
+	if err != nil {
+		return nil, fmt.Errorf("totp secret encryption failed: %w", err)
+	}
+
+	saved, err := u.mfaRepo.SavePendingMFA(&domain.UserMFA{
+		UserID:          userID,
+		SecretEncrypted: encrypted,
+		CreatedAt:       time.Now(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("totp enrollment failed: %w", err)
+	}
+	if !saved {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	return &TOTPEnrollment{
+		Secret:     secret,
+		OTPAuthURI: shared.TOTPURI(totpIssuer, user.Email, secret),
+	}, nil
+}
+
+func (u *mfaUsecase) ConfirmTOTPEnrollment(userID, code string) ([]string, error) {
+	mfa, err := u.mfaRepo.FindMFA(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve mfa: %w", err)
+	}
+	if mfa == nil {
+		return nil, ErrMFANotEnrolled
+	}
+	if mfa.EnabledAt != nil {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	// 登録の確認ではリカバリーコードはまだ存在しないため、TOTP のみ受け付ける
+	secret, err := u.openSecret(mfa)
+	if err != nil {
+		return nil, err
+	}
+	step, ok := shared.ValidateTOTP(secret, normalizeMFACode(code), time.Now())
+	if !ok {
+		return nil, ErrInvalidMFACode
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := u.mfaRepo.EnableMFA(userID, step, hashes); err != nil {
+		return nil, fmt.Errorf("totp enrollment failed: %w", err)
+	}
+	return codes, nil
+}
+
+func (u *mfaUsecase) RegenerateRecoveryCodes(userID, code string) ([]string, error) {
+	if err := u.requireValidCode(userID, code); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := u.mfaRepo.ReplaceRecoveryCodes(userID, hashes); err != nil {
+		return nil, fmt.Errorf("recovery code generation failed: %w", err)
+	}
+	return codes, nil
+}
+
+func (u *mfaUsecase) DisableMFA(userID, code string) error {
+	if err := u.requireValidCode(userID, code); err != nil {
+		return err
+	}
+
+	if err := u.mfaRepo.DeleteMFA(userID); err != nil {
+		return fmt.Errorf("failed to disable mfa: %w", err)
+	}
+	return nil
+}
+
+func (u *mfaUsecase) IsMFAEnabled(userID string) (bool, error) {
+	mfa, err := u.mfaRepo.FindMFA(userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to retrieve mfa: %w", err)
+	}
+	return mfa != nil && mfa.EnabledAt != nil, nil
+}
+
+func (u *mfaUsecase) StartChallenge(userID string) (*MFAChallenge, error) {
+	token, err := shared.GenerateOpaqueToken()
+	if err != nil {
+		return nil, fmt.Errorf("token generation failed: %w", err)
+	}
+
+	now := time.Now()
+	challenge := &domain.ActionToken{
+		TokenHash: shared.HashOpaqueToken(token),
+		UserID:    userID,
+		Purpose:   domain.ActionTokenMFAChallenge,
+		ExpiresAt: now.Add(mfaChallengeTTL),
+		CreatedAt: now,
+	}
+	if err := u.tokenRepo.CreateActionToken(challenge); err != nil {
+		return nil, fmt.Errorf("mfa challenge creation failed: %w", err)
+	}
+
+	return &MFAChallenge{MFAToken: token, ExpiresIn: int(mfaChallengeTTL.Seconds())}, nil
+}
+
+func (u *mfaUsecase) CompleteChallenge(mfaToken, code string, client ClientInfo) (*AuthTokens, error) {
+	tokenHash := shared.HashOpaqueToken(mfaToken)
+	// 6桁のコードを総当たりされないよう、検証前に試行回数を確保する（上限に達したチャレンジは無効）
+	challenge, err := u.tokenRepo.ReserveActionTokenAttempt(tokenHash, domain.ActionTokenMFAChallenge, maxMFAChallengeAttempt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find mfa challenge: %w", err)
+	}
+	if challenge == nil {
+		return nil, ErrInvalidMFAChallenge
+	}
+
+	mfa, err := u.mfaRepo.FindMFA(challenge.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve mfa: %w", err)
+	}
+	if mfa == nil || mfa.EnabledAt == nil {
+		return nil, ErrInvalidMFAChallenge
+	}
+
+	ok, err := u.verifyCodeWithLockout(mfa, code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrInvalidMFACode
+	}
+
+	// 同じチャレンジで並行してログインを完了させない
+	consumed, err := u.tokenRepo.ConsumeActionToken(tokenHash, domain.ActionTokenMFAChallenge)
+	if err != nil {
+		return nil, fmt.Errorf("failed to consume mfa challenge: %w", err)
+	}
+	if consumed == nil {
+		return nil, ErrInvalidMFAChallenge
+	}
+
+	return u.authUc.IssueTokens(challenge.UserID, client)
+}
+
+func (u *mfaUsecase) requireValidCode(userID, code string) error {
+	mfa, err := u.mfaRepo.FindMFA(userID)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve mfa: %w", err)
+	}
+	if mfa == nil || mfa.EnabledAt == nil {
+		return ErrMFANotEnabled
+	}
+
+	ok, err := u.verifyCodeWithLockout(mfa, code)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrInvalidMFACode
+	}
+	return nil
+}
+
+// verifyCodeWithLockout は verifyCode にユーザー単位の連続失敗の制限を加える
+// ログインと設定変更で失敗回数を共有し、検証前に試行を失敗として数える。ロック中は検証せずに ErrMFALocked を返す
+func (u *mfaUsecase) verifyCodeWithLockout(mfa *domain.UserMFA, code string) (bool, error) {
+	reserved, err := u.mfaRepo.ReserveMFAAttempt(mfa.UserID, maxMFAFailedAttempt, mfaLockoutDuration)
+	if err != nil {
+		return false, err
+	}
+	if !reserved {
+		return false, ErrMFALocked
+	}
+
+	ok, err := u.verifyCode(mfa, code)
+	if err != nil || !ok {
+		return false, err
+	}
+
+	if err := u.mfaRepo.ResetMFAFailures(mfa.UserID); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// verifyCode は6桁のコードを TOTP、それ以外をリカバリーコードとして検証する
+// どちらも一度使ったコードは再利用できない
+func (u *mfaUsecase) verifyCode(mfa *domain.UserMFA, code string) (bool, error) {
+	code = normalizeMFACode(code)
+
+	if len(code) != recoveryCodeLength {
+		secret, err := u.openSecret(mfa)
+		if err != nil {
+			return false, err
+		}
+		step, ok := shared.ValidateTOTP(secret, code, time.Now())
+		if !ok {
+			return false, nil
+		}
+		fresh, err := u.mfaRepo.UpdateLastUsedStep(mfa.UserID, step)
+		if err != nil {
+			return false, fmt.Errorf("failed to record totp step: %w", err)
+		}
+		return fresh, nil
+	}
+
+	consumed, err := u.mfaRepo.ConsumeRecoveryCode(mfa.UserID, shared.HashOpaqueToken(code))
+	if err != nil {
+		return false, fmt.Errorf("failed to consume recovery code: %w", err)
+	}
+	return consumed, nil
+}
+
+// openSecret は TOTP の秘密鍵を復号する（暗号化鍵が設定されていない場合は ErrMFAUnavailable）
+func (u *mfaUsecase) openSecret(mfa *domain.UserMFA) (string, error) {
+	if u.secretBox == nil {
+		return "", ErrMFAUnavailable
+	}
+	secret, err := u.secretBox.Open(mfa.SecretEncrypted, mfa.UserID)
+	if err != nil {
+		return "", fmt.Errorf("totp secret decryption failed: %w", err)
+	}
+	return secret, nil
+}
+
+// normalizeMFACode は入力時の区切り文字や大文字小文字の違いを吸収する
+func normalizeMFACode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
+
+const recoveryCodeAlphabet = "abcdefghijklmnopqrstuvwxyz234567"
+
+// generateRecoveryCodes は "xxxxx-xxxxx" 形式のリカバリーコードと、保存用のハッシュを返す
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+
+	buf := make([]byte, recoveryCodeLength)
+	for i := 0; i < recoveryCodeCount; i++ {
+		if _, err := rand.Read(buf); err != nil {
+			return nil, nil, fmt.Errorf("recovery code generation failed: %w", err)
+		}
+		// 256 は 32 で割り切れるため、剰余を取っても偏りは生じない
+		raw := make([]byte, recoveryCodeLength)
+		for j, b := range buf {
+			raw[j] = recoveryCodeAlphabet[int(b)%len(recoveryCodeAlphabet)]
+		}
+		code := string(raw)
+		codes = append(codes, code[:recoveryCodeLength/2]+"-"+code[recoveryCodeLength/2:])
+		hashes = append(hashes, shared.HashOpaqueToken(code))
+	}
+	return codes, hashes, nil
+}
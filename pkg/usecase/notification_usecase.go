@@ -0,0 +1,201 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/k-kanke/ashiato-backend/pkg/domain"
+	"github.com/k-kanke/ashiato-backend/pkg/repository"
+	"github.com/k-kanke/ashiato-backend/pkg/shared"
+)
+
+type NotificationUsecase interface {
+	// フレンド申請を受け取ったことを通知する
+	NotifyFriendRequest(requesterID, targetID string) error
+
+	// フレンド申請が承認されたことを申請者に通知する
+	NotifyFriendAccepted(accepterID, requesterID string) error
+
+	// 自分のピンにコメントが付いたことを通知する
+	NotifyComment(commenterID string, pin *domain.Pin) error
+
+	// フレンドが新しいピンを投稿したことを通知する
+	NotifyFriendNewPin(pin *domain.Pin) error
+
+	// ピンの所有者にリアクションを通知する
+	NotifyReaction(reactorID string, pin *domain.Pin) error
+
+	// 通知一覧を新しい順に取得する（次ページのカーソルも返す）
+	GetNotifications(userID, cursor string, limit int) ([]domain.Notification, string, error)
+
+	// 通知を既読にする
+	MarkAsRead(userID, notificationID string) error
+
+	// 全ての通知を既読にする
+	MarkAllAsRead(userID string) error
+
+	// 未読件数を取得する
+	GetUnreadCount(userID string) (int, error)
+}
+
+type notificationUsecase struct {
+	notificationRepo repository.NotificationRepository
+	userRepo         repository.UserRepository
+	hub              repository.EventHub
+}
+
+func NewNotificationUsecase(
+	notificationRepo repository.NotificationRepository,
+	userRepo repository.UserRepository,
+	hub repository.EventHub,
+) NotificationUsecase {
+	return &notificationUsecase{
+		notificationRepo: notificationRepo,
+		userRepo:         userRepo,
+		hub:              hub,
+	}
+}
+
+var ErrNotificationNotFound = errors.New("notification not found")
+
+func (u *notificationUsecase) NotifyFriendRequest(requesterID, targetID string) error {
+	return u.notify(targetID, requesterID, domain.NotificationTypeFriendRequest, "", func(s *domain.UserSettings) bool {
+		return s.FriendRequestReceived
+	})
+}
+
+func (u *notificationUsecase) NotifyFriendAccepted(accepterID, requesterID string) error {
+	return u.notify(requesterID, accepterID, domain.NotificationTypeFriendAccepted, "", func(s *domain.UserSettings) bool {
+		return s.FriendRequestAccepted
+	})
+}
+
+func (u *notificationUsecase) NotifyComment(commenterID string, pin *domain.Pin) error {
+	// 自分のピンへの自分のコメントは通知しない
+	if pin.UserID == commenterID {
+		return nil
+	}
+	return u.notify(pin.UserID, commenterID, domain.NotificationTypeComment, pin.PinID, func(s *domain.UserSettings) bool {
+		return s.CommentOnMyPin
+	})
+}
+
+func (u *notificationUsecase) NotifyFriendNewPin(pin *domain.Pin) error {
+	// フレンドごとの設定チェックはリポジトリ側でまとめて行う
+	notifications, err := u.notificationRepo.CreateFriendNewPinNotifications(pin.UserID, pin.PinID, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to notify friends of new pin: %w", err)
+	}
+
+	for i := range notifications {
+		u.publish(&notifications[i])
+	}
+	return nil
+}
+
+func (u *notificationUsecase) NotifyReaction(reactorID string, pin *domain.Pin) error {
+	// 自分のピンへの自分のリアクションは通知しない
+	if pin.UserID == reactorID {
+		return nil
+	}
+	return u.notify(pin.UserID, reactorID, domain.NotificationTypeReaction, pin.PinID, func(s *domain.UserSettings) bool {
+		return s.ReactionOnMyPin
+	})
+}
+
+// notify は受信者の通知設定を確認し、有効な場合のみ通知を作成する
+func (u *notificationUsecase) notify(
+	recipientID, actorID, notificationType, relatedEntityID string,
+	enabled func(*domain.UserSettings) bool,
+) error {
+	_, settings, err := u.userRepo.FindUserByID(recipientID)
+	if err != nil {
+		return fmt.Errorf("failed to load notification settings: %w", err)
+	}
+	// 設定レコードがない場合はスキーマのデフォルト（全て有効）として扱う
+	if settings != nil && !enabled(settings) {
+		return nil
+	}
+
+	notification := &domain.Notification{
+		NotificationID:  uuid.New().String(),
+		RecipientUserID: recipientID,
+		ActorUserID:     actorID,
+		Type:            notificationType,
+		RelatedEntityID: relatedEntityID,
+		IsRead:          false,
+		CreatedAt:       time.Now(),
+	}
+
+	if err := u.notificationRepo.CreateNotification(notification); err != nil {
+		return fmt.Errorf("failed to create notification: %w", err)
+	}
+
+	u.publish(notification)
+	return nil
+}
+
+// publish は接続中の受信者へ通知をリアルタイム配信する
+func (u *notificationUsecase) publish(notification *domain.Notification) {
+	u.hub.PublishToUser(notification.RecipientUserID, domain.StreamEvent{
+		Type: domain.StreamEventNotification,
+		Data: notification,
+	})
+}
+
+func (u *notificationUsecase) GetNotifications(userID, cursor string, limit int) ([]domain.Notification, string, error) {
+	before, err := shared.DecodeCursor(cursor)
+	if err != nil {
+		return nil, "", ErrInvalidPageCursor
+	}
+
+	limit = normalizePageSize(limit)
+
+	// 1件多く取得し、次ページの有無を判定する
+	notifications, err := u.notificationRepo.GetNotifications(userID, before, limit+1)
+	if err != nil {
+		return nil, "", fmt.Errorf("usecase failed to get notifications: %w", err)
+	}
+
+	nextCursor := ""
+	if len(notifications) > limit {
+		notifications = notifications[:limit]
+		last := notifications[len(notifications)-1]
+		nextCursor = shared.EncodeCursor(last.CreatedAt, last.NotificationID)
+	}
+
+	return notifications, nextCursor, nil
+}
+
+func (u *notificationUsecase) MarkAsRead(userID, notificationID string) error {
+	if _, err := uuid.Parse(notificationID); err != nil {
+		return ErrNotificationNotFound
+	}
+
+	found, err := u.notificationRepo.MarkAsRead(userID, notificationID)
+	if err != nil {
+		return fmt.Errorf("failed to mark notification as read: %w", err)
+	}
+	if !found {
+		return ErrNotificationNotFound
+	}
+
+	return nil
+}
+
+func (u *notificationUsecase) MarkAllAsRead(userID string) error {
+	if err := u.notificationRepo.MarkAllAsRead(userID); err != nil {
+		return fmt.Errorf("failed to mark all notifications as read: %w", err)
+	}
+	return nil
+}
+
+func (u *notificationUsecase) GetUnreadCount(userID string) (int, error) {
+	count, err := u.notificationRepo.CountUnread(userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count unread notifications: %w", err)
+	}
+	return count, nil
+}
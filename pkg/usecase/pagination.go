@@ -0,0 +1,17 @@
+package usecase
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// normalizePageSize はクライアント指定の件数を許容範囲に丸める
+func normalizePageSize(limit int) int {
+	if limit <= 0 {
+		return defaultPageSize
+	}
+	if limit > maxPageSize {
+		return maxPageSize
+	}
+	return limit
+}
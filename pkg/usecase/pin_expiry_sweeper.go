@@ -0,0 +1,38 @@
+package usecase
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// StartPinExpirySweeper は一定間隔で公開期限切れのピンを expired に移すバックグラウンド処理を開始する
+// 戻り値の関数で停止する
+func StartPinExpirySweeper(pinUc PinUsecase, interval time.Duration) (stop func()) {
+	done := make(chan struct{})
+	ticker := time.NewTicker(interval)
+
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				expired, err := pinUc.ExpirePins()
+				if err != nil {
+					log.Printf("pin expiry sweep failed: %v", err)
+					continue
+				}
+				if expired > 0 {
+					log.Printf("expired %d pins", expired)
+				}
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(done) })
+	}
+}
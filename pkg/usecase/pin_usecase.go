@@ -5,23 +5,28 @@ package usecase
 import (
 	"errors"
 	"fmt"
+	"io"
+	"log"
 	"math"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/k-kanke/ashiato-backend/pkg/domain"
 	"github.com/k-kanke/ashiato-backend/pkg/repository"
+	"github.com/k-kanke/ashiato-backend/pkg/shared/geofile"
 )
 
 type PinUsecase interface {
 	// 新規ピンを作成
 	PostNewPin(
 		userID string,
-		lat float64,
-		lng float64,
+		claim LocationClaim,
 		content string,
-		mediaURL string,
+		mediaID string, // POST /v1/media で発行したメディアID（空の場合はメディアなし）
 		privacy string,
+		audienceID string,
+		expiresAt *time.Time, // nil の場合は無期限
 	) (*domain.Pin, error)
 
 	// 地図表示用のピンを取得
@@ -33,47 +38,201 @@ type PinUsecase interface {
 		maxLng float64,
 		privacy string,
 	) ([]domain.Pin, error)
+
+	// ズームレベルに応じてピンまたはクラスタを取得
+	GetMapView(
+		userID string,
+		minLat float64,
+		maxLat float64,
+		minLng float64,
+		maxLng float64,
+		zoom int,
+		privacy string,
+	) (*MapView, error)
+
+	// ベクタータイル (MVT) 形式でピンを取得
+	GetPinTile(userID string, z, x, y int) ([]byte, error)
+
+	// 現在地から半径内のピンを距離の近い順に取得
+	GetNearbyPins(
+		userID string,
+		lat float64,
+		lng float64,
+		radiusMeters float64,
+		limit int,
+	) ([]domain.NearbyPin, error)
+
+	// 自分のピンを指定形式 (geojson/gpx/kml) で w に書き出す
+	ExportPins(userID, format string, w io.Writer) error
+
+	// GPX/GeoJSON ファイルからピンを一括作成
+	ImportPins(userID, format string, r io.Reader, defaultPrivacy string) (*ImportResult, error)
+
+	// ユーザーの足あとを時系列順の軌跡として取得
+	GetTrail(viewerID, ownerID string, from, to time.Time) (*Trail, error)
+
+	// ピンをIDで取得
+	GetPin(userID, pinID string) (*domain.Pin, error)
+
+	// ピンを編集（所有者のみ）
+	UpdatePin(userID, pinID string, update PinUpdate) (*domain.Pin, error)
+
+	// ピンを論理削除（所有者のみ）
+	DeletePin(userID, pinID string) error
+
+	// 公開期限を過ぎたピンを expired にする（スイーパーから定期的に呼ばれる）
+	ExpirePins() (int64, error)
+}
+
+// MapView は地図表示用の結果。クラスタ表示時は Clusters のみ、それ以外は Pins のみを含む
+type MapView struct {
+	Zoom      int                 `json:"zoom"`
+	Clustered bool                `json:"clustered"`
+	Pins      []domain.Pin        `json:"pins"`
+	Clusters  []domain.PinCluster `json:"clusters"`
+}
+
+// ImportResult は一括インポートの結果。Errors の index はファイル内での地点の順番
+type ImportResult struct {
+	Imported int                    `json:"imported"`
+	Errors   []geofile.FeatureError `json:"errors"`
+}
+
+// Trail はユーザーの足あとを古い順につないだ軌跡
+// SegmentDistancesMeters[i] は Pins[i] から Pins[i+1] までの距離
+type Trail struct {
+	UserID                 string        `json:"user_id"`
+	From                   time.Time     `json:"from"`
+	To                     time.Time     `json:"to"`
+	Geometry               TrailGeometry `json:"geometry"`
+	Pins                   []domain.Pin  `json:"pins"`
+	SegmentDistancesMeters []float64     `json:"segment_distances_m"`
+	TotalDistanceMeters    float64       `json:"total_distance_m"`
+}
+
+// TrailGeometry は GeoJSON の LineString（座標は [経度, 緯度]）
+type TrailGeometry struct {
+	Type        string       `json:"type"`
+	Coordinates [][2]float64 `json:"coordinates"`
+}
+
+// PinUpdate はピン編集時の変更内容。nil のフィールドは変更しない
+type PinUpdate struct {
+	ContentText    *string
+	MediaID        *string // 空文字の場合はメディアを外す
+	PrivacySetting *string
+	AudienceID     *string // privacy_setting が audience の場合の公開先
+	Status         *string // active または archived
 }
 
 type pinUsecase struct {
-	pinRepo repository.PinRepository
+	pinRepo        repository.PinRepository
+	notificationUc NotificationUsecase
+	hub            repository.EventHub
+	verifier       LocationVerifier
+	audienceRepo   repository.AudienceRepository
+	mediaRepo      repository.MediaRepository
+	userRepo       repository.UserRepository
 	// ... 他のリポジトリ
 }
 
-func NewPinUsecase(pinRepo repository.PinRepository) PinUsecase {
-	return &pinUsecase{pinRepo: pinRepo}
+func NewPinUsecase(
+	pinRepo repository.PinRepository,
+	nu NotificationUsecase,
+	hub repository.EventHub,
+	verifier LocationVerifier,
+	audienceRepo repository.AudienceRepository,
+	mediaRepo repository.MediaRepository,
+	userRepo repository.UserRepository,
+) PinUsecase {
+	return &pinUsecase{
+		pinRepo:        pinRepo,
+		notificationUc: nu,
+		hub:            hub,
+		verifier:       verifier,
+		audienceRepo:   audienceRepo,
+		mediaRepo:      mediaRepo,
+		userRepo:       userRepo,
+	}
 }
 
 var (
 	ErrInvalidPinCoordinates = errors.New("invalid pin coordinates")
-	ErrPinLocationDeviation  = errors.New("pin location deviation too large")
+	ErrInvalidBoundingBox    = errors.New("invalid map bounding box coordinates")
+	ErrInvalidSearchRadius   = errors.New("invalid search radius")
+	ErrInvalidZoomLevel      = errors.New("invalid zoom level")
+	ErrInvalidTileCoordinate = errors.New("invalid tile coordinate")
+	ErrUnsupportedFileFormat = errors.New("unsupported file format")
+	ErrInvalidImportFile     = errors.New("invalid import file")
+	ErrInvalidTrailRequest   = errors.New("invalid trail request")
+	ErrPinNotFound           = errors.New("pin not found")
+	ErrPinForbidden          = errors.New("not allowed to modify this pin")
+	ErrInvalidPinUpdate      = errors.New("invalid pin update")
+	ErrInvalidPinPrivacy     = errors.New("invalid pin privacy setting")
+	ErrInvalidPrivacyFilter  = errors.New("invalid privacy filter")
+	ErrInvalidPinExpiry      = errors.New("invalid pin expiry")
+	ErrInvalidPinMedia       = errors.New("invalid pin media")
+)
+
+// 一時的なピンの公開期間の範囲
+const (
+	minPinLifetime = 5 * time.Minute
+	maxPinLifetime = 30 * 24 * time.Hour
+)
+
+// 地図の表示フィルタ（閲覧可能なピンをさらに絞り込む）
+const (
+	PinFilterAll     = "all"     // 閲覧可能な全てのピン
+	PinFilterPublic  = "public"  // 公開ピンのみ
+	PinFilterFriends = "friends" // フレンドが投稿したピンのみ
+	PinFilterMine    = "mine"    // 自分のピンのみ
 )
 
 // PostNewPin は新規Pin投稿の全ロジックを実行する
 func (u *pinUsecase) PostNewPin(
 	userID string,
-	lat, lng float64,
+	claim LocationClaim,
 	content string,
-	mediaURL string,
+	mediaID string,
 	privacy string,
+	audienceID string,
+	expiresAt *time.Time,
 ) (*domain.Pin, error) {
-	if err := u.validatePinLocation(userID, lat, lng); err != nil {
+	if err := u.validatePinPrivacy(userID, privacy, audienceID); err != nil {
 		return nil, err
 	}
+	mediaURL, err := u.resolvePinMedia(userID, mediaID)
+	if err != nil {
+		return nil, err
+	}
+	if expiresAt != nil {
+		lifetime := time.Until(*expiresAt)
+		if lifetime < minPinLifetime || lifetime > maxPinLifetime {
+			return nil, fmt.Errorf("%w: must be between %s and %s from now", ErrInvalidPinExpiry, minPinLifetime, maxPinLifetime)
+		}
+	}
 
-	// 1. 位置認証ロジック (例: 過去のアクセス履歴や、現在地の厳密な検証)
-	// ここで位置情報の偽装チェックを行う
-	// if !u.CheckLocationValidity(userID, lat, lng) { return nil, errors.New("location not verified") }
+	claim.UserID = userID
+	if claim.ClaimedAt.IsZero() {
+		claim.ClaimedAt = time.Now()
+	}
+
+	// 座標の妥当性と位置情報の偽装チェックを行う
+	if err := u.validatePinLocation(&claim); err != nil {
+		return nil, err
+	}
 
 	newPin := &domain.Pin{
 		PinID:          uuid.New().String(),
 		UserID:         userID,
-		Latitude:       lat,
-		Longitude:      lng,
+		Latitude:       claim.Latitude,
+		Longitude:      claim.Longitude,
 		ContentText:    content,
 		MediaURL:       mediaURL,
 		PrivacySetting: privacy,
-		Status:         "active", // デフォルトはアクティブ
+		AudienceID:     audienceID,
+		Status:         domain.PinStatusActive, // デフォルトはアクティブ
+		ExpiresAt:      expiresAt,
 		CreatedAt:      time.Now(),
 	}
 
@@ -81,6 +240,14 @@ func (u *pinUsecase) PostNewPin(
 		return nil, fmt.Errorf("pin creation failed: %w", err)
 	}
 
+	// 地図を購読中のクライアントへ配信（公開範囲の判定は購読側で行う）
+	u.hub.Broadcast(domain.StreamEvent{Type: domain.StreamEventPin, Data: newPin})
+
+	// フレンドに新規ピンを通知（通知の失敗で投稿自体は失敗させない）
+	if err := u.notificationUc.NotifyFriendNewPin(newPin); err != nil {
+		log.Printf("failed to notify friends of new pin: %v", err)
+	}
+
 	return newPin, nil
 }
 
@@ -90,9 +257,12 @@ func (u *pinUsecase) GetPinsForMap(
 	minLat, maxLat, minLng, maxLng float64,
 	privacy string,
 ) ([]domain.Pin, error) {
-	// 1. バリデーション: 矩形範囲が妥当かチェック
+	// 1. バリデーション: 矩形範囲と表示フィルタが妥当かチェック
 	if minLat >= maxLat || minLng >= maxLng {
-		return nil, errors.New("invalid map bounding box coordinates")
+		return nil, ErrInvalidBoundingBox
+	}
+	if err := validatePrivacyFilter(privacy); err != nil {
+		return nil, err
 	}
 
 	// 2. リポジトリの呼び出し
@@ -101,42 +271,493 @@ func (u *pinUsecase) GetPinsForMap(
 		return nil, fmt.Errorf("usecase failed to get pins: %w", err)
 	}
 
+	// 3. リアクション数の付与
+	if err := u.attachReactions(userID, pins); err != nil {
+		return nil, err
+	}
+
 	return pins, nil
 }
 
-func (u *pinUsecase) validatePinLocation(userID string, lat, lng float64) error {
-	if math.IsNaN(lat) || math.IsNaN(lng) {
-		return fmt.Errorf("%w: NaN detected", ErrInvalidPinCoordinates)
+// クラスタリングの設定
+const (
+	maxZoomLevel        = 22
+	clusterMaxZoom      = 14 // このズーム以下ではクラスタのみを返す
+	clusterCellsPerTile = 4  // 256pxタイルあたりのグリッド数（約64px四方のセル）
+)
+
+// GetMapView はズームレベルに応じて個別のピンかクラスタを返す
+func (u *pinUsecase) GetMapView(
+	userID string,
+	minLat, maxLat, minLng, maxLng float64,
+	zoom int,
+	privacy string,
+) (*MapView, error) {
+	if zoom < 0 || zoom > maxZoomLevel {
+		return nil, fmt.Errorf("%w: must be between 0 and %d", ErrInvalidZoomLevel, maxZoomLevel)
 	}
-	if lat < -90 || lat > 90 || lng < -180 || lng > 180 {
-		return fmt.Errorf("%w: coordinates out of range", ErrInvalidPinCoordinates)
+
+	view := &MapView{
+		Zoom:     zoom,
+		Pins:     make([]domain.Pin, 0),
+		Clusters: make([]domain.PinCluster, 0),
+	}
+
+	// 十分にズームインしている場合は個別のピンを返す
+	if zoom > clusterMaxZoom {
+		pins, err := u.GetPinsForMap(userID, minLat, maxLat, minLng, maxLng, privacy)
+		if err != nil {
+			return nil, err
+		}
+		view.Pins = pins
+		return view, nil
 	}
 
-	latestPin, err := u.pinRepo.GetMostRecentPin(userID)
+	if minLat >= maxLat || minLng >= maxLng {
+		return nil, ErrInvalidBoundingBox
+	}
+	if err := validatePrivacyFilter(privacy); err != nil {
+		return nil, err
+	}
+
+	clusters, err := u.pinRepo.GetPinClustersInArea(
+		userID,
+		minLat, maxLat, minLng, maxLng,
+		clusterGridSizeDegrees(zoom),
+		privacy,
+	)
 	if err != nil {
-		return fmt.Errorf("location validation failed: %w", err)
+		return nil, fmt.Errorf("usecase failed to get pin clusters: %w", err)
 	}
+	view.Clustered = true
+	view.Clusters = clusters
 
-	// 初回投稿はそのまま許可
-	if latestPin == nil {
-		return nil
+	return view, nil
+}
+
+// clusterGridSizeDegrees はズームレベルに対応するグリッドの一辺（度）を返す
+// ズームが1上がるごとに表示範囲は半分になるため、グリッドも半分にする
+func clusterGridSizeDegrees(zoom int) float64 {
+	return 360.0 / (math.Exp2(float64(zoom)) * clusterCellsPerTile)
+}
+
+// GetPinTile は地図クライアント向けのベクタータイルを生成する
+func (u *pinUsecase) GetPinTile(userID string, z, x, y int) ([]byte, error) {
+	if z < 0 || z > maxZoomLevel {
+		return nil, fmt.Errorf("%w: zoom must be between 0 and %d", ErrInvalidTileCoordinate, maxZoomLevel)
+	}
+	tilesPerAxis := 1 << uint(z)
+	if x < 0 || x >= tilesPerAxis || y < 0 || y >= tilesPerAxis {
+		return nil, fmt.Errorf("%w: x and y must be between 0 and %d", ErrInvalidTileCoordinate, tilesPerAxis-1)
+	}
+
+	tile, err := u.pinRepo.GetPinTile(userID, z, x, y)
+	if err != nil {
+		return nil, fmt.Errorf("usecase failed to get pin tile: %w", err)
+	}
+
+	return tile, nil
+}
+
+// 周辺検索の半径の上限とデフォルト
+const (
+	defaultNearbyRadiusMeters = 1000.0
+	maxNearbyRadiusMeters     = 50000.0
+)
+
+// GetNearbyPins は「近くの足あと」表示のためのPinを取得する
+func (u *pinUsecase) GetNearbyPins(
+	userID string,
+	lat, lng float64,
+	radiusMeters float64,
+	limit int,
+) ([]domain.NearbyPin, error) {
+	if math.IsNaN(lat) || math.IsNaN(lng) || lat < -90 || lat > 90 || lng < -180 || lng > 180 {
+		return nil, fmt.Errorf("%w: coordinates out of range", ErrInvalidPinCoordinates)
+	}
+
+	if radiusMeters == 0 {
+		radiusMeters = defaultNearbyRadiusMeters
 	}
+	if math.IsNaN(radiusMeters) || radiusMeters < 0 || radiusMeters > maxNearbyRadiusMeters {
+		return nil, fmt.Errorf("%w: must be between 0 and %.0fm", ErrInvalidSearchRadius, maxNearbyRadiusMeters)
+	}
+
+	pins, err := u.pinRepo.GetNearbyPins(userID, lat, lng, radiusMeters, normalizePageSize(limit))
+	if err != nil {
+		return nil, fmt.Errorf("usecase failed to get nearby pins: %w", err)
+	}
+
+	return pins, nil
+}
 
-	// 一定時間以上経過している場合は距離チェックを緩和
-	const driftGraceDuration = 6 * time.Hour
-	if time.Since(latestPin.CreatedAt) > driftGraceDuration {
+// ExportPins はユーザーの全てのピンを1件ずつ読み出しながら書き出す
+func (u *pinUsecase) ExportPins(userID, format string, w io.Writer) error {
+	writer, err := geofile.NewWriter(format, w)
+	if err != nil {
+		return fmt.Errorf("%w: %q", ErrUnsupportedFileFormat, format)
+	}
+
+	if err := u.pinRepo.ForEachPinByUser(userID, writer.WritePin); err != nil {
+		return fmt.Errorf("pin export failed: %w", err)
+	}
+
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("pin export failed: %w", err)
+	}
+
+	return nil
+}
+
+// 1回のインポートで作成できるピンの上限
+const maxImportPins = 5000
+
+// ImportPins はファイル内の地点を元のタイムスタンプのままインポート済みピンとして作成する
+// 過去の記録であるため LocationVerifier による位置検証は行わない
+func (u *pinUsecase) ImportPins(userID, format string, r io.Reader, defaultPrivacy string) (*ImportResult, error) {
+	points, featureErrors, err := geofile.Decode(format, r)
+	if err != nil {
+		if errors.Is(err, geofile.ErrUnsupportedFormat) {
+			return nil, fmt.Errorf("%w: %q", ErrUnsupportedFileFormat, format)
+		}
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImportFile, err)
+	}
+	if len(points) > maxImportPins {
+		return nil, fmt.Errorf("%w: too many points (max %d)", ErrInvalidImportFile, maxImportPins)
+	}
+
+	result := &ImportResult{Errors: make([]geofile.FeatureError, 0, len(featureErrors))}
+	result.Errors = append(result.Errors, featureErrors...)
+
+	verified, err := u.userRepo.IsEmailVerified(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check email verification: %w", err)
+	}
+
+	pins := make([]*domain.Pin, 0, len(points))
+	for _, point := range points {
+		privacy := point.PrivacySetting
+		if privacy == "" {
+			privacy = defaultPrivacy
+		}
+		// オーディエンスリストはファイルからは指定できない
+		if privacy != domain.PinPrivacyPublic && privacy != domain.PinPrivacyFriends && privacy != domain.PinPrivacyPrivate {
+			result.Errors = append(result.Errors, geofile.FeatureError{
+				Index:   point.Index,
+				Message: fmt.Sprintf("unsupported privacy_setting %q", privacy),
+			})
+			continue
+		}
+		if privacy == domain.PinPrivacyPublic && !verified {
+			result.Errors = append(result.Errors, geofile.FeatureError{
+				Index:   point.Index,
+				Message: "verify your email address to import public pins",
+			})
+			continue
+		}
+
+		// 任意のURLを参照させないため、ファイル内のメディアURLは取り込まない
+		pins = append(pins, &domain.Pin{
+			PinID:          uuid.New().String(),
+			UserID:         userID,
+			Latitude:       point.Latitude,
+			Longitude:      point.Longitude,
+			ContentText:    point.ContentText,
+			PrivacySetting: privacy,
+			Status:         domain.PinStatusActive,
+			IsImported:     true,
+			CreatedAt:      point.Time,
+		})
+	}
+
+	if len(pins) > 0 {
+		if err := u.pinRepo.CreatePins(pins); err != nil {
+			return nil, fmt.Errorf("pin import failed: %w", err)
+		}
+	}
+	result.Imported = len(pins)
+
+	return result, nil
+}
+
+// 軌跡の取得期間の設定
+const (
+	defaultTrailPeriod = 7 * 24 * time.Hour
+	maxTrailPeriod     = 90 * 24 * time.Hour
+	maxTrailPins       = 1000
+)
+
+// GetTrail は閲覧者に見えるピンだけで軌跡を組み立てる
+// from / to がゼロ値の場合は直近1週間とする
+func (u *pinUsecase) GetTrail(viewerID, ownerID string, from, to time.Time) (*Trail, error) {
+	if _, err := uuid.Parse(ownerID); err != nil {
+		return nil, fmt.Errorf("%w: malformed user id", ErrInvalidTrailRequest)
+	}
+
+	if to.IsZero() {
+		to = time.Now()
+	}
+	if from.IsZero() {
+		from = to.Add(-defaultTrailPeriod)
+	}
+	if !from.Before(to) {
+		return nil, fmt.Errorf("%w: from must be before to", ErrInvalidTrailRequest)
+	}
+	if to.Sub(from) > maxTrailPeriod {
+		return nil, fmt.Errorf("%w: period must be %d days or less", ErrInvalidTrailRequest, int(maxTrailPeriod.Hours()/24))
+	}
+
+	pins, err := u.pinRepo.GetVisiblePinsByUser(viewerID, ownerID, from, to, maxTrailPins)
+	if err != nil {
+		return nil, fmt.Errorf("usecase failed to get trail: %w", err)
+	}
+
+	trail := &Trail{
+		UserID: ownerID,
+		From:   from,
+		To:     to,
+		Geometry: TrailGeometry{
+			Type:        "LineString",
+			Coordinates: make([][2]float64, 0, len(pins)),
+		},
+		Pins:                   pins,
+		SegmentDistancesMeters: make([]float64, 0, len(pins)),
+	}
+
+	for i, pin := range pins {
+		trail.Geometry.Coordinates = append(trail.Geometry.Coordinates, [2]float64{pin.Longitude, pin.Latitude})
+		if i == 0 {
+			continue
+		}
+		prev := pins[i-1]
+		distance := haversineMeters(prev.Latitude, prev.Longitude, pin.Latitude, pin.Longitude)
+		trail.SegmentDistancesMeters = append(trail.SegmentDistancesMeters, distance)
+		trail.TotalDistanceMeters += distance
+	}
+
+	return trail, nil
+}
+
+// GetPin は閲覧権限のあるピンを1件取得する
+func (u *pinUsecase) GetPin(userID, pinID string) (*domain.Pin, error) {
+	if _, err := uuid.Parse(pinID); err != nil {
+		return nil, ErrPinNotFound
+	}
+
+	pin, err := u.pinRepo.FindVisiblePin(userID, pinID)
+	if err != nil {
+		return nil, fmt.Errorf("usecase failed to get pin: %w", err)
+	}
+	// 存在しないピンと閲覧権限のないピンは区別しない
+	if pin == nil {
+		return nil, ErrPinNotFound
+	}
+
+	pins := []domain.Pin{*pin}
+	if err := u.attachReactions(userID, pins); err != nil {
+		return nil, err
+	}
+
+	return &pins[0], nil
+}
+
+// attachReactions はピンごとに絵文字別のリアクション数と閲覧者自身のリアクションを付与する
+func (u *pinUsecase) attachReactions(viewerID string, pins []domain.Pin) error {
+	if len(pins) == 0 {
 		return nil
 	}
 
-	const permissibleDriftMeters = 50000.0 // 50km 以上離れていたら再認証を求める
-	distance := haversineMeters(latestPin.Latitude, latestPin.Longitude, lat, lng)
-	if distance > permissibleDriftMeters {
-		return fmt.Errorf("%w: deviation %.0fm exceeds %.0fm", ErrPinLocationDeviation, distance, permissibleDriftMeters)
+	pinIDs := make([]string, len(pins))
+	indexByID := make(map[string]int, len(pins))
+	for i := range pins {
+		pinIDs[i] = pins[i].PinID
+		indexByID[pins[i].PinID] = i
+	}
+
+	counts, err := u.pinRepo.GetReactionCounts(viewerID, pinIDs)
+	if err != nil {
+		return fmt.Errorf("usecase failed to get reactions: %w", err)
+	}
+
+	for _, count := range counts {
+		if i, ok := indexByID[count.PinID]; ok {
+			pins[i].Reactions = append(pins[i].Reactions, count)
+		}
+	}
+	return nil
+}
+
+// UpdatePin はピンの本文・メディア・公開設定・状態を更新する
+func (u *pinUsecase) UpdatePin(userID, pinID string, update PinUpdate) (*domain.Pin, error) {
+	pin, err := u.getOwnPin(userID, pinID)
+	if err != nil {
+		return nil, err
+	}
+
+	if update.ContentText != nil {
+		if strings.TrimSpace(*update.ContentText) == "" {
+			return nil, fmt.Errorf("%w: content_text is empty", ErrInvalidPinUpdate)
+		}
+		pin.ContentText = *update.ContentText
+	}
+	if update.MediaID != nil {
+		mediaURL, err := u.resolvePinMedia(userID, *update.MediaID)
+		if err != nil {
+			return nil, err
+		}
+		pin.MediaURL = mediaURL
+	}
+	if update.PrivacySetting != nil {
+		pin.PrivacySetting = *update.PrivacySetting
+	}
+	if update.AudienceID != nil {
+		pin.AudienceID = *update.AudienceID
+	}
+	if pin.PrivacySetting != domain.PinPrivacyAudience {
+		pin.AudienceID = ""
+	}
+	if update.PrivacySetting != nil || update.AudienceID != nil {
+		if err := u.validatePinPrivacy(userID, pin.PrivacySetting, pin.AudienceID); err != nil {
+			return nil, err
+		}
+	}
+	if update.Status != nil {
+		// 削除は DeletePin からのみ行う
+		if *update.Status != domain.PinStatusActive && *update.Status != domain.PinStatusArchived {
+			return nil, fmt.Errorf("%w: unsupported status %q", ErrInvalidPinUpdate, *update.Status)
+		}
+		// 期限切れのピンは再公開できない（アーカイブは可能）
+		if pin.Status == domain.PinStatusExpired && *update.Status == domain.PinStatusActive {
+			return nil, fmt.Errorf("%w: expired pin cannot be reactivated", ErrInvalidPinUpdate)
+		}
+		pin.Status = *update.Status
+	}
+
+	if err := u.pinRepo.UpdatePin(pin); err != nil {
+		return nil, fmt.Errorf("pin update failed: %w", err)
+	}
+
+	return pin, nil
+}
+
+// ExpirePins は公開期限を過ぎたピンを expired にする
+// 表示は各クエリで期限を直接判定しているため、ここでは状態を揃えるだけ
+func (u *pinUsecase) ExpirePins() (int64, error) {
+	expired, err := u.pinRepo.ExpirePins(time.Now())
+	if err != nil {
+		return 0, fmt.Errorf("pin expiry failed: %w", err)
+	}
+	return expired, nil
+}
+
+// DeletePin はピンを削除状態にする（行自体は残す）
+func (u *pinUsecase) DeletePin(userID, pinID string) error {
+	pin, err := u.getOwnPin(userID, pinID)
+	if err != nil {
+		return err
+	}
+
+	pin.Status = domain.PinStatusDeleted
+	if err := u.pinRepo.UpdatePin(pin); err != nil {
+		return fmt.Errorf("pin deletion failed: %w", err)
 	}
 
 	return nil
 }
 
+// getOwnPin は所有者本人のピンのみを返す
+func (u *pinUsecase) getOwnPin(userID, pinID string) (*domain.Pin, error) {
+	pin, err := u.GetPin(userID, pinID)
+	if err != nil {
+		return nil, err
+	}
+	if pin.UserID != userID {
+		return nil, ErrPinForbidden
+	}
+	return pin, nil
+}
+
+// validatePinPrivacy は公開範囲と、オーディエンス限定の場合の公開先が投稿者自身のリストであるかを検証する
+func (u *pinUsecase) validatePinPrivacy(userID, privacy, audienceID string) error {
+	switch privacy {
+	case domain.PinPrivacyPublic, domain.PinPrivacyFriends, domain.PinPrivacyPrivate:
+		if audienceID != "" {
+			return fmt.Errorf("%w: audience_id is only allowed for audience pins", ErrInvalidPinPrivacy)
+		}
+		// 使い捨てアカウントによるスパムを防ぐため、全体公開はメールアドレスの確認後に限る
+		if privacy == domain.PinPrivacyPublic {
+			verified, err := u.userRepo.IsEmailVerified(userID)
+			if err != nil {
+				return fmt.Errorf("failed to check email verification: %w", err)
+			}
+			if !verified {
+				return fmt.Errorf("%w: verify your email address to post public pins", ErrEmailNotVerified)
+			}
+		}
+		return nil
+	case domain.PinPrivacyAudience:
+		if audienceID == "" {
+			return fmt.Errorf("%w: audience_id is required", ErrInvalidPinPrivacy)
+		}
+		if _, err := uuid.Parse(audienceID); err != nil {
+			return fmt.Errorf("%w: audience not found", ErrInvalidPinPrivacy)
+		}
+		audience, err := u.audienceRepo.FindAudienceByID(audienceID)
+		if err != nil {
+			return fmt.Errorf("failed to retrieve audience: %w", err)
+		}
+		if audience == nil || audience.UserID != userID {
+			return fmt.Errorf("%w: audience not found", ErrInvalidPinPrivacy)
+		}
+		return nil
+	default:
+		return fmt.Errorf("%w: unsupported privacy_setting %q", ErrInvalidPinPrivacy, privacy)
+	}
+}
+
+// resolvePinMedia は投稿者自身がアップロードしたメディアであることを確認し、配信用のURLを返す
+func (u *pinUsecase) resolvePinMedia(userID, mediaID string) (string, error) {
+	if mediaID == "" {
+		return "", nil
+	}
+	if _, err := uuid.Parse(mediaID); err != nil {
+		return "", fmt.Errorf("%w: media not found", ErrInvalidPinMedia)
+	}
+
+	item, err := u.mediaRepo.FindMediaByID(mediaID)
+	if err != nil {
+		return "", fmt.Errorf("failed to retrieve media: %w", err)
+	}
+	if item == nil || item.UserID != userID {
+		return "", fmt.Errorf("%w: media not found", ErrInvalidPinMedia)
+	}
+	return mediaURL(item.MediaID), nil
+}
+
+func validatePrivacyFilter(filter string) error {
+	switch filter {
+	case PinFilterAll, PinFilterPublic, PinFilterFriends, PinFilterMine:
+		return nil
+	default:
+		return fmt.Errorf("%w: %q", ErrInvalidPrivacyFilter, filter)
+	}
+}
+
+func (u *pinUsecase) validatePinLocation(claim *LocationClaim) error {
+	lat, lng := claim.Latitude, claim.Longitude
+	if math.IsNaN(lat) || math.IsNaN(lng) {
+		return fmt.Errorf("%w: NaN detected", ErrInvalidPinCoordinates)
+	}
+	if lat < -90 || lat > 90 || lng < -180 || lng > 180 {
+		return fmt.Errorf("%w: coordinates out of range", ErrInvalidPinCoordinates)
+	}
+
+	if u.verifier == nil {
+		return nil
+	}
+	return u.verifier.Verify(claim)
+}
+
 func haversineMeters(lat1, lng1, lat2, lng2 float64) float64 {
 	const earthRadiusMeters = 6371000.0
 
@@ -0,0 +1,130 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/k-kanke/ashiato-backend/pkg/domain"
+	"github.com/k-kanke/ashiato-backend/pkg/repository"
+)
+
+type ReactionUsecase interface {
+	// ピンに絵文字でリアクションする（同じ絵文字での重複は無視する）
+	AddReaction(userID, pinID, emoji string) error
+
+	// 自分のリアクションを取り消す
+	RemoveReaction(userID, pinID, emoji string) error
+}
+
+type reactionUsecase struct {
+	pinRepo        repository.PinRepository
+	notificationUc NotificationUsecase
+}
+
+func NewReactionUsecase(pinRepo repository.PinRepository, nu NotificationUsecase) ReactionUsecase {
+	return &reactionUsecase{pinRepo: pinRepo, notificationUc: nu}
+}
+
+var (
+	ErrInvalidReaction  = errors.New("invalid reaction emoji")
+	ErrReactionNotFound = errors.New("reaction not found")
+)
+
+// 1つのリアクションに含められる最大のコードポイント数（肌の色・ZWJ 連結を含む）
+const maxReactionRunes = 10
+
+// AddReaction はピンの閲覧権限を確認したうえでリアクションを作成する
+func (u *reactionUsecase) AddReaction(userID, pinID, emoji string) error {
+	if err := validateReactionEmoji(emoji); err != nil {
+		return err
+	}
+
+	// 見えないピンにはリアクションさせない
+	pin, err := u.findVisiblePin(userID, pinID)
+	if err != nil {
+		return err
+	}
+
+	created, err := u.pinRepo.CreateReaction(&domain.Reaction{
+		PinID:     pinID,
+		UserID:    userID,
+		Emoji:     emoji,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("reaction creation failed: %w", err)
+	}
+
+	// 付け直しで通知が重複しないよう、新規作成時のみ通知する（通知の失敗でリアクション自体は失敗させない）
+	if created {
+		if err := u.notificationUc.NotifyReaction(userID, pin); err != nil {
+			log.Printf("failed to notify reaction: %v", err)
+		}
+	}
+
+	return nil
+}
+
+func (u *reactionUsecase) RemoveReaction(userID, pinID, emoji string) error {
+	if err := validateReactionEmoji(emoji); err != nil {
+		return err
+	}
+	if _, err := u.findVisiblePin(userID, pinID); err != nil {
+		return err
+	}
+
+	deleted, err := u.pinRepo.DeleteReaction(pinID, userID, emoji)
+	if err != nil {
+		return fmt.Errorf("reaction deletion failed: %w", err)
+	}
+	if !deleted {
+		return ErrReactionNotFound
+	}
+	return nil
+}
+
+func (u *reactionUsecase) findVisiblePin(userID, pinID string) (*domain.Pin, error) {
+	if _, err := uuid.Parse(pinID); err != nil {
+		return nil, ErrPinNotFound
+	}
+
+	pin, err := u.pinRepo.FindVisiblePin(userID, pinID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find pin: %w", err)
+	}
+	// 存在しないピンと閲覧権限のないピンは区別しない
+	if pin == nil {
+		return nil, ErrPinNotFound
+	}
+
+	return pin, nil
+}
+
+// validateReactionEmoji は絵文字1つ分の文字列であることを確認する
+// 記号 (So)・修飾子 (Sk: 肌の色)・囲み記号 (Me: キーキャップ)・ZWJ・異体字セレクタのみを許可する
+func validateReactionEmoji(emoji string) error {
+	if emoji == "" || !utf8.ValidString(emoji) || utf8.RuneCountInString(emoji) > maxReactionRunes {
+		return ErrInvalidReaction
+	}
+
+	hasSymbol := false
+	for _, r := range emoji {
+		switch {
+		case unicode.Is(unicode.So, r):
+			hasSymbol = true
+		case unicode.Is(unicode.Sk, r), unicode.Is(unicode.Me, r):
+		case r == '\u200d', r == '\ufe0f':
+		default:
+			return ErrInvalidReaction
+		}
+	}
+	if !hasSymbol {
+		return ErrInvalidReaction
+	}
+	return nil
+}
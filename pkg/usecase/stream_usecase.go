@@ -0,0 +1,179 @@
+package usecase
+
+import (
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/k-kanke/ashiato-backend/pkg/domain"
+	"github.com/k-kanke/ashiato-backend/pkg/repository"
+	"github.com/k-kanke/ashiato-backend/pkg/shared"
+)
+
+type StreamUsecase interface {
+	// 通知と、指定範囲内の新規ピンを受け取るストリームを開く
+	// セッションが失効するかアクセストークンの期限が切れると、session_ended を送ってストリームを閉じる
+	OpenStream(claims *shared.Claims, bounds *MapBounds) (*Stream, error)
+}
+
+// MapBounds は購読する地図の矩形範囲
+type MapBounds struct {
+	MinLat float64
+	MaxLat float64
+	MinLng float64
+	MaxLng float64
+}
+
+func (b *MapBounds) Contains(lat, lng float64) bool {
+	return b.containsWithin(lat, lng, 0)
+}
+
+// containsWithin は矩形を margin 度だけ広げた範囲に含まれるか
+func (b *MapBounds) containsWithin(lat, lng, margin float64) bool {
+	return lat >= b.MinLat-margin && lat <= b.MaxLat+margin && lng >= b.MinLng-margin && lng <= b.MaxLng+margin
+}
+
+// Stream は1接続分のイベントストリーム。使い終わったら必ず Close する
+type Stream struct {
+	Events <-chan domain.StreamEvent
+	close  func()
+}
+
+func (s *Stream) Close() {
+	s.close()
+}
+
+type streamUsecase struct {
+	pinRepo repository.PinRepository
+	hub     repository.EventHub
+	authUc  AuthUsecase
+}
+
+func NewStreamUsecase(pinRepo repository.PinRepository, hub repository.EventHub, authUc AuthUsecase) StreamUsecase {
+	return &streamUsecase{pinRepo: pinRepo, hub: hub, authUc: authUc}
+}
+
+const streamBufferSize = 16
+
+// streamSessionCheckInterval は接続中にセッションの失効を確認する間隔
+const streamSessionCheckInterval = 25 * time.Second
+
+// streamFuzzMarginDegrees はプライバシーゾーンのぼかしで表示位置が実際の位置から離れうる最大量（度）。
+// 実際の位置がこの分だけ広げた範囲にも入らないピンは、公開範囲を問い合わせずに除外する
+const streamFuzzMarginDegrees = 0.01
+
+func (u *streamUsecase) OpenStream(claims *shared.Claims, bounds *MapBounds) (*Stream, error) {
+	if bounds != nil && (bounds.MinLat >= bounds.MaxLat || bounds.MinLng >= bounds.MaxLng) {
+		return nil, ErrInvalidBoundingBox
+	}
+	if claims.ExpiresAt == nil {
+		return nil, ErrInvalidAccessToken
+	}
+
+	userID := claims.UserID
+	events, unsubscribe := u.hub.Subscribe(userID)
+	out := make(chan domain.StreamEvent, streamBufferSize)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(out)
+
+		sessionCheck := time.NewTicker(streamSessionCheckInterval)
+		defer sessionCheck.Stop()
+		tokenExpiry := time.NewTimer(time.Until(claims.ExpiresAt.Time))
+		defer tokenExpiry.Stop()
+
+		// 失効後も通知やフレンド限定のピンを配信し続けないよう、理由を伝えてストリームを閉じる
+		end := func(reason string) {
+			select {
+			case out <- domain.StreamEvent{Type: domain.StreamEventSessionEnded, Data: map[string]string{"reason": reason}}:
+			case <-done:
+			}
+		}
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-tokenExpiry.C:
+				end("token_expired")
+				return
+			case <-sessionCheck.C:
+				if err := u.authUc.VerifySession(claims); err != nil {
+					switch {
+					case errors.Is(err, ErrSessionRevoked):
+						end("session_revoked")
+					case errors.Is(err, ErrInvalidAccessToken):
+						end("token_expired")
+					default:
+						log.Printf("failed to check session for stream: %v", err)
+						end("session_check_failed")
+					}
+					return
+				}
+			case event, ok := <-events:
+				if !ok {
+					return
+				}
+				event, ok = u.prepareDelivery(userID, bounds, event)
+				if !ok {
+					continue
+				}
+				select {
+				case out <- event:
+				case <-done:
+					return
+				}
+			}
+		}
+	}()
+
+	var once sync.Once
+	return &Stream{
+		Events: out,
+		close: func() {
+			once.Do(func() {
+				close(done)
+				unsubscribe()
+			})
+		},
+	}, nil
+}
+
+// prepareDelivery はイベントを購読者に配信してよいか判定し、購読者向けの内容に置き換える
+func (u *streamUsecase) prepareDelivery(
+	userID string,
+	bounds *MapBounds,
+	event domain.StreamEvent,
+) (domain.StreamEvent, bool) {
+	switch event.Type {
+	case domain.StreamEventNotification:
+		// 通知はハブ側で受信者宛てにのみ配信されている
+		return event, true
+	case domain.StreamEventPin:
+		pin, ok := event.Data.(*domain.Pin)
+		if !ok || bounds == nil {
+			return event, false
+		}
+		if pin.UserID == userID {
+			return event, bounds.Contains(pin.Latitude, pin.Longitude)
+		}
+		// 新規ピンごとに全購読者がクエリを発行しないよう、範囲から明らかに外れるピンは先に除外する
+		if !bounds.containsWithin(pin.Latitude, pin.Longitude, streamFuzzMarginDegrees) {
+			return event, false
+		}
+		// 公開範囲の判定とプライバシーゾーンによるぼかしは地図表示 (GetPinsInArea) と同じクエリに任せる
+		visible, err := u.pinRepo.FindVisiblePin(userID, pin.PinID)
+		if err != nil {
+			log.Printf("failed to check pin visibility for stream: %v", err)
+			return event, false
+		}
+		if visible == nil || !bounds.Contains(visible.Latitude, visible.Longitude) {
+			return event, false
+		}
+		return domain.StreamEvent{Type: event.Type, Data: visible}, true
+	default:
+		return event, false
+	}
+}
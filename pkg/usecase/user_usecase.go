@@ -1,9 +1,13 @@
 package usecase
 
 import (
+	"database/sql"
+	"errors"
 	"fmt"
-	"os"
-	"strconv"
+	"log"
+	"math"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -15,41 +19,124 @@ import (
 
 type UserUsecase interface {
 	// 新規ユーザーを登録し、認証トークンを返す
-	RegisterUser(username, email, password string) (token string, err error)
+	RegisterUser(username, email, password string, client ClientInfo) (*AuthTokens, error)
 
 	// ユーザーを認証し、認証トークンを返す
-	AuthenticateUser(email, password string) (token string, err error)
+	// 二要素認証が有効なユーザーにはトークンの代わりにチャレンジを返す
+	AuthenticateUser(email, password string, client ClientInfo) (*LoginResult, error)
 
 	// ユーザーのプロフィールを取得
 	GetUserProfile(userID string) (*ProfileResponse, error)
 
+	// メールアドレス確認用のメールを再送する
+	ResendEmailVerification(userID string) error
+
+	// メールで送ったトークンでメールアドレスを確認済みにする
+	VerifyEmail(token string) error
+
+	// パスワード再設定用のメールをバックグラウンドで送る（登録されていないアドレスでもエラーにしない）
+	RequestPasswordReset(email string) error
+
+	// メールで送ったトークンでパスワードを再設定し、全てのセッションを失効させる
+	ResetPassword(token, newPassword string) error
+
+	// プライバシーゾーンを追加する
+	CreatePrivacyZone(userID, name string, lat, lng, radiusMeters float64, mode string) (*domain.PrivacyZone, error)
+
+	// 自分のプライバシーゾーン一覧を取得
+	GetPrivacyZones(userID string) ([]domain.PrivacyZone, error)
+
+	// プライバシーゾーンを削除する
+	DeletePrivacyZone(userID, zoneID string) error
+
 	// その他のプロフィール更新、フレンド管理メソッド
 }
 
 type userUsecase struct {
-	userRepo repository.UserRepository
+	userRepo  repository.UserRepository
+	tokenRepo repository.TokenRepository
+	authUc    AuthUsecase
+	mfaUc     MFAUsecase
+	mailer    repository.Mailer
+	config    AccountConfig
+}
+
+// LoginResult はトークンか、二要素認証待ちのチャレンジのどちらか一方を持つ
+type LoginResult struct {
+	Tokens       *AuthTokens
+	MFAChallenge *MFAChallenge
 }
 
 type ProfileResponse struct {
 	UserID                string `json:"user_id"`
 	Username              string `json:"username"`
 	Email                 string `json:"email"`
+	EmailVerified         bool   `json:"email_verified"`
+	MFAEnabled            bool   `json:"mfa_enabled"`
 	CommentOnMyPin        bool   `json:"comment_on_my_pin"`
 	FriendNewPin          bool   `json:"friend_new_pin"`
 	FriendRequestReceived bool   `json:"friend_request_received"`
 	FriendRequestAccepted bool   `json:"friend_request_accepted"`
+	ReactionOnMyPin       bool   `json:"reaction_on_my_pin"`
 	CreatedAt             string `json:"created_at"`
 }
 
-func NewUserUsecase(userRepo repository.UserRepository) UserUsecase {
-	return &userUsecase{userRepo: userRepo}
+// AccountConfig はアカウント管理メールのリンク先と、メール内トークンの有効期間
+type AccountConfig struct {
+	AppBaseURL           string // メール内のリンクを開くクライアントのURL
+	EmailVerificationTTL time.Duration
+	PasswordResetTTL     time.Duration
 }
 
-func (u *userUsecase) RegisterUser(username, email, password string) (token string, err error) {
+func DefaultAccountConfig() AccountConfig {
+	return AccountConfig{
+		AppBaseURL:           "http://localhost:3001",
+		EmailVerificationTTL: 24 * time.Hour,
+		PasswordResetTTL:     time.Hour,
+	}
+}
+
+func NewUserUsecase(
+	userRepo repository.UserRepository,
+	tokenRepo repository.TokenRepository,
+	authUc AuthUsecase,
+	mfaUc MFAUsecase,
+	mailer repository.Mailer,
+	config AccountConfig,
+) UserUsecase {
+	return &userUsecase{
+		userRepo:  userRepo,
+		tokenRepo: tokenRepo,
+		authUc:    authUc,
+		mfaUc:     mfaUc,
+		mailer:    mailer,
+		config:    config,
+	}
+}
+
+var (
+	ErrEmailNotVerified     = errors.New("email address is not verified")
+	ErrEmailAlreadyVerified = errors.New("email address is already verified")
+	ErrInvalidActionToken   = errors.New("invalid or expired token")
+)
+
+var (
+	ErrInvalidPrivacyZone      = errors.New("invalid privacy zone")
+	ErrPrivacyZoneLimitReached = errors.New("privacy zone limit reached")
+	ErrPrivacyZoneNotFound     = errors.New("privacy zone not found")
+)
+
+const (
+	maxPrivacyZonesPerUser = 10
+	minPrivacyZoneRadiusM  = 100.0
+	maxPrivacyZoneRadiusM  = 5000.0
+)
+
+func (u *userUsecase) RegisterUser(username, email, password string, client ClientInfo) (*AuthTokens, error) {
 	// パスワードのハッシュ化
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	// ユーザーIDとデフォルト設定の準備
@@ -66,77 +153,61 @@ func (u *userUsecase) RegisterUser(username, email, password string) (token stri
 		// ... その他のデフォルト値
 	}
 
+	// 通知設定はスキーマのデフォルトと同じく全て有効にする
 	defaultSettings := &domain.UserSettings{
-		UserID:         userID,
-		CommentOnMyPin: true,
-		// ... その他のデフォルト設定
+		UserID:                userID,
+		CommentOnMyPin:        true,
+		FriendNewPin:          true,
+		FriendRequestReceived: true,
+		FriendRequestAccepted: true,
+		ReactionOnMyPin:       true,
 	}
 
 	// リポジトリ経由でDBに保存
 	if err := u.userRepo.CreateUser(newUser, defaultSettings); err != nil {
 		// メール重複エラーの処理など
-		return "", fmt.Errorf("registration failed: %w", err)
-	}
-
-	secret := os.Getenv("JWT_SECRET")
-	if secret == "" {
-		return "", fmt.Errorf("missing JWT secret")
-	}
-
-	expiryStr := os.Getenv("TOKEN_EXPIRY_HOURS")
-	expiryHours := 24 // default 1 day
-	if expiryStr != "" {
-		if parsed, parseErr := strconv.Atoi(expiryStr); parseErr == nil {
-			expiryHours = parsed
-		} else {
-			return "", fmt.Errorf("invalid TOKEN_EXPIRY_HOURS: %w", parseErr)
-		}
+		return nil, fmt.Errorf("registration failed: %w", err)
 	}
 
-	token, err = shared.GenerateToken(newUser.UserID, secret, expiryHours)
-	if err != nil {
-		return "", fmt.Errorf("token generation failed: %w", err)
+	// 確認メールの送信に失敗しても登録は完了させる（再送できる）
+	if err := u.sendEmailVerification(newUser); err != nil {
+		log.Printf("failed to send verification email to user %s: %v", newUser.UserID, err)
 	}
 
-	return token, nil
+	return u.authUc.IssueTokens(newUser.UserID, client)
 }
 
-func (u *userUsecase) AuthenticateUser(email, password string) (token string, err error) {
+func (u *userUsecase) AuthenticateUser(email, password string, client ClientInfo) (*LoginResult, error) {
 	user, err := u.userRepo.FindUserByEmail(email)
 	if err != nil {
-		return "", fmt.Errorf("user not found: %w", err)
+		return nil, fmt.Errorf("user not found: %w", err)
 	}
 
 	err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password))
 	if err != nil {
 		if err == bcrypt.ErrMismatchedHashAndPassword {
-			return "", fmt.Errorf("invalid credentials")
+			return nil, fmt.Errorf("invalid credentials")
 		}
-		return "", fmt.Errorf("authentication error: %w", err)
+		return nil, fmt.Errorf("authentication error: %w", err)
 	}
 
-	secret := os.Getenv("JWT_SECRET")
-	if secret == "" {
-		return "", fmt.Errorf("missing JWT secret")
+	mfaEnabled, err := u.mfaUc.IsMFAEnabled(user.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("authentication error: %w", err)
 	}
-
-	expiryStr := os.Getenv("TOKEN_EXPIRY_HOURS")
-	expiryHours := 24 // default 1 day
-	if expiryStr != "" {
-		if parsed, parseErr := strconv.Atoi(expiryStr); parseErr == nil {
-			expiryHours = parsed
-		} else {
-			return "", fmt.Errorf("invalid TOKEN_EXPIRY_HOURS: %w", parseErr)
+	if mfaEnabled {
+		challenge, err := u.mfaUc.StartChallenge(user.UserID)
+		if err != nil {
+			return nil, fmt.Errorf("authentication error: %w", err)
 		}
+		return &LoginResult{MFAChallenge: challenge}, nil
 	}
 
-	token, err = shared.GenerateToken(user.UserID, secret, expiryHours)
+	tokens, err := u.authUc.IssueTokens(user.UserID, client)
 	if err != nil {
-		return "", fmt.Errorf("token generation failed: %w", err)
+		return nil, err
 	}
-
-	return token, nil
-
+	return &LoginResult{Tokens: tokens}, nil
 }
 
 // GetUserProfile はユーザー情報と設定をまとめて返す
@@ -147,10 +218,11 @@ func (u *userUsecase) GetUserProfile(userID string) (*ProfileResponse, error) {
 	}
 
 	resp := &ProfileResponse{
-		UserID:    user.UserID,
-		Username:  user.Username,
-		Email:     user.Email,
-		CreatedAt: user.CreatedAt.Format("2006-01-02 15:04:05"),
+		UserID:        user.UserID,
+		Username:      user.Username,
+		Email:         user.Email,
+		EmailVerified: user.EmailVerifiedAt != nil,
+		CreatedAt:     user.CreatedAt.Format("2006-01-02 15:04:05"),
 	}
 
 	if settings != nil {
@@ -158,7 +230,235 @@ func (u *userUsecase) GetUserProfile(userID string) (*ProfileResponse, error) {
 		resp.FriendNewPin = settings.FriendNewPin
 		resp.FriendRequestReceived = settings.FriendRequestReceived
 		resp.FriendRequestAccepted = settings.FriendRequestAccepted
+		resp.ReactionOnMyPin = settings.ReactionOnMyPin
+	}
+
+	resp.MFAEnabled, err = u.mfaUc.IsMFAEnabled(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve user profile: %w", err)
 	}
 
 	return resp, nil
 }
+
+func (u *userUsecase) ResendEmailVerification(userID string) error {
+	user, _, err := u.userRepo.FindUserByID(userID)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve user: %w", err)
+	}
+	if user.EmailVerifiedAt != nil {
+		return ErrEmailAlreadyVerified
+	}
+
+	if err := u.sendEmailVerification(user); err != nil {
+		return fmt.Errorf("failed to send verification email: %w", err)
+	}
+	return nil
+}
+
+func (u *userUsecase) VerifyEmail(token string) error {
+	actionToken, err := u.tokenRepo.ConsumeActionToken(shared.HashOpaqueToken(token), domain.ActionTokenEmailVerification)
+	if err != nil {
+		return fmt.Errorf("email verification failed: %w", err)
+	}
+	if actionToken == nil {
+		return ErrInvalidActionToken
+	}
+
+	if err := u.userRepo.MarkEmailVerified(actionToken.UserID); err != nil {
+		return fmt.Errorf("email verification failed: %w", err)
+	}
+	return nil
+}
+
+// passwordResetCooldown は同じユーザーへのパスワード再設定メールの最短送信間隔。
+// 第三者が繰り返しリクエストして、受信箱を埋めたり送信済みのリンクを無効にし続けたりできないようにする
+const passwordResetCooldown = 5 * time.Minute
+
+// RequestPasswordReset は登録済みかどうかを推測されないよう、常に成功を返す。
+// 応答時間やメール送信の失敗からも判別できないよう、ユーザーの検索から送信までをバックグラウンドで行う
+func (u *userUsecase) RequestPasswordReset(email string) error {
+	go func() {
+		if err := u.sendPasswordReset(email); err != nil {
+			log.Printf("failed to send password reset email: %v", err)
+		}
+	}()
+	return nil
+}
+
+func (u *userUsecase) sendPasswordReset(email string) error {
+	user, err := u.userRepo.FindUserByEmail(email)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return fmt.Errorf("failed to find user: %w", err)
+	}
+
+	token, actionToken, err := newActionToken(user.UserID, domain.ActionTokenPasswordReset, u.config.PasswordResetTTL)
+	if err != nil {
+		return err
+	}
+	created, err := u.tokenRepo.CreateActionTokenAfterCooldown(actionToken, passwordResetCooldown)
+	if err != nil {
+		return fmt.Errorf("token creation failed: %w", err)
+	}
+	if !created {
+		// 直前に送ったリンクを有効なまま残す
+		return nil
+	}
+
+	message := &domain.MailMessage{
+		To:      user.Email,
+		Subject: "Reset your Ashiato password",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nOpen the link below to choose a new password. The link expires in %s.\n\n%s\n\nIf you did not request a password reset, you can ignore this email.\n",
+			user.Username, formatLinkLifetime(u.config.PasswordResetTTL), u.actionLink("/reset-password", token),
+		),
+	}
+	return u.mailer.Send(message)
+}
+
+// ResetPassword は途中で失敗してもパスワードだけが変わって古いセッションが残ることのないよう、
+// トークンの消費からセッションの失効までを1トランザクションで行う
+func (u *userUsecase) ResetPassword(token, newPassword string) error {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	reset, err := u.tokenRepo.ResetPasswordWithToken(shared.HashOpaqueToken(token), string(hashedPassword))
+	if err != nil {
+		return fmt.Errorf("password reset failed: %w", err)
+	}
+	if !reset {
+		return ErrInvalidActionToken
+	}
+	return nil
+}
+
+func (u *userUsecase) sendEmailVerification(user *domain.User) error {
+	token, err := u.issueActionToken(user.UserID, domain.ActionTokenEmailVerification, u.config.EmailVerificationTTL)
+	if err != nil {
+		return err
+	}
+
+	message := &domain.MailMessage{
+		To:      user.Email,
+		Subject: "Verify your Ashiato email address",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nOpen the link below to verify your email address. The link expires in %s.\n\n%s\n",
+			user.Username, formatLinkLifetime(u.config.EmailVerificationTTL), u.actionLink("/verify-email", token),
+		),
+	}
+	return u.mailer.Send(message)
+}
+
+func (u *userUsecase) issueActionToken(userID, purpose string, ttl time.Duration) (string, error) {
+	token, actionToken, err := newActionToken(userID, purpose, ttl)
+	if err != nil {
+		return "", err
+	}
+	if err := u.tokenRepo.CreateActionToken(actionToken); err != nil {
+		return "", fmt.Errorf("token creation failed: %w", err)
+	}
+	return token, nil
+}
+
+// newActionToken はメールに載せるトークンと、保存用のハッシュを持つ ActionToken を生成する
+func newActionToken(userID, purpose string, ttl time.Duration) (string, *domain.ActionToken, error) {
+	token, err := shared.GenerateOpaqueToken()
+	if err != nil {
+		return "", nil, fmt.Errorf("token generation failed: %w", err)
+	}
+
+	now := time.Now()
+	return token, &domain.ActionToken{
+		TokenHash: shared.HashOpaqueToken(token),
+		UserID:    userID,
+		Purpose:   purpose,
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	}, nil
+}
+
+func (u *userUsecase) actionLink(path, token string) string {
+	return strings.TrimRight(u.config.AppBaseURL, "/") + path + "?token=" + url.QueryEscape(token)
+}
+
+// formatLinkLifetime はメール本文に載せるリンクの有効期間を "24 hours" のような形にする
+func formatLinkLifetime(ttl time.Duration) string {
+	if ttl >= time.Hour && ttl%time.Hour == 0 {
+		if ttl == time.Hour {
+			return "1 hour"
+		}
+		return fmt.Sprintf("%d hours", int(ttl.Hours()))
+	}
+	return fmt.Sprintf("%d minutes", int(ttl.Minutes()))
+}
+
+// CreatePrivacyZone は自宅や職場の周辺など、ピンの位置を隠す範囲を追加する
+func (u *userUsecase) CreatePrivacyZone(
+	userID, name string,
+	lat, lng, radiusMeters float64,
+	mode string,
+) (*domain.PrivacyZone, error) {
+	if math.IsNaN(lat) || math.IsNaN(lng) || lat < -90 || lat > 90 || lng < -180 || lng > 180 {
+		return nil, fmt.Errorf("%w: coordinates out of range", ErrInvalidPrivacyZone)
+	}
+	if radiusMeters < minPrivacyZoneRadiusM || radiusMeters > maxPrivacyZoneRadiusM {
+		return nil, fmt.Errorf("%w: radius must be between %.0fm and %.0fm",
+			ErrInvalidPrivacyZone, minPrivacyZoneRadiusM, maxPrivacyZoneRadiusM)
+	}
+	if mode != domain.PrivacyZoneModeHide && mode != domain.PrivacyZoneModeFuzz {
+		return nil, fmt.Errorf("%w: unknown mode %q", ErrInvalidPrivacyZone, mode)
+	}
+
+	zones, err := u.userRepo.GetPrivacyZones(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve privacy zones: %w", err)
+	}
+	if len(zones) >= maxPrivacyZonesPerUser {
+		return nil, ErrPrivacyZoneLimitReached
+	}
+
+	zone := &domain.PrivacyZone{
+		ZoneID:       uuid.New().String(),
+		UserID:       userID,
+		Name:         name,
+		Latitude:     lat,
+		Longitude:    lng,
+		RadiusMeters: radiusMeters,
+		Mode:         mode,
+		CreatedAt:    time.Now(),
+	}
+
+	if err := u.userRepo.CreatePrivacyZone(zone); err != nil {
+		return nil, fmt.Errorf("privacy zone creation failed: %w", err)
+	}
+
+	return zone, nil
+}
+
+func (u *userUsecase) GetPrivacyZones(userID string) ([]domain.PrivacyZone, error) {
+	zones, err := u.userRepo.GetPrivacyZones(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve privacy zones: %w", err)
+	}
+	return zones, nil
+}
+
+func (u *userUsecase) DeletePrivacyZone(userID, zoneID string) error {
+	if _, err := uuid.Parse(zoneID); err != nil {
+		return ErrPrivacyZoneNotFound
+	}
+
+	deleted, err := u.userRepo.DeletePrivacyZone(userID, zoneID)
+	if err != nil {
+		return fmt.Errorf("privacy zone deletion failed: %w", err)
+	}
+	if !deleted {
+		return ErrPrivacyZoneNotFound
+	}
+	return nil
+}
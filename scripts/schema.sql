@@ -7,18 +7,133 @@ CREATE TABLE IF NOT EXISTS users (
     profile_image_url TEXT,
     bio VARCHAR(500),
     is_banned BOOLEAN DEFAULT FALSE,
+    email_verified_at TIMESTAMP WITH TIME ZONE,
     created_at TIMESTAMP WITH TIME ZONE NOT NULL,
     updated_at TIMESTAMP WITH TIME ZONE NOT NULL
 );
 
 
+-- セッションテーブル (ログインした端末ごとに1行)
+CREATE TABLE IF NOT EXISTS sessions (
+    session_id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    user_agent VARCHAR(255) NOT NULL DEFAULT '',
+    ip_address VARCHAR(45) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    last_seen_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    revoked_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_sessions_user ON sessions (user_id) WHERE revoked_at IS NULL;
+
+
+-- リフレッシュトークンテーブル (ローテーションごとに1行。同じセッションのトークンは session_id を共有する)
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    token_id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    session_id UUID NOT NULL REFERENCES sessions(session_id) ON DELETE CASCADE,
+    token_hash CHAR(64) UNIQUE NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    used_at TIMESTAMP WITH TIME ZONE,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_session ON refresh_tokens (session_id);
+
+
+-- メールアドレス確認・パスワード再設定・二要素認証待ちのログイン用の使い捨てトークンテーブル
+CREATE TABLE IF NOT EXISTS action_tokens (
+    token_hash CHAR(64) PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    purpose VARCHAR(30) NOT NULL CHECK (purpose IN ('email_verification', 'password_reset', 'mfa_challenge')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    used_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_action_tokens_user ON action_tokens (user_id, purpose) WHERE used_at IS NULL;
+
+
+-- 二要素認証テーブル (TOTP の秘密鍵はアプリケーション側で暗号化して保存する)
+CREATE TABLE IF NOT EXISTS user_mfa (
+    user_id UUID PRIMARY KEY REFERENCES users(user_id) ON DELETE CASCADE,
+    secret_encrypted TEXT NOT NULL,
+    enabled_at TIMESTAMP WITH TIME ZONE,
+    last_used_step BIGINT NOT NULL DEFAULT 0,
+    -- コードの総当たりを防ぐため、連続で失敗するとしばらく検証を受け付けない
+    failed_attempts INTEGER NOT NULL DEFAULT 0,
+    locked_until TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+
+-- 二要素認証のリカバリーコードテーブル (コードは SHA-256 のみ保存する)
+CREATE TABLE IF NOT EXISTS mfa_recovery_codes (
+    user_id UUID NOT NULL REFERENCES user_mfa(user_id) ON DELETE CASCADE,
+    code_hash CHAR(64) NOT NULL,
+    used_at TIMESTAMP WITH TIME ZONE,
+    PRIMARY KEY (user_id, code_hash)
+);
+
+
 -- ユーザー設定テーブル
 CREATE TABLE IF NOT EXISTS user_settings (
     user_id UUID PRIMARY KEY REFERENCES users(user_id) ON DELETE CASCADE,
     comment_on_my_pin BOOLEAN DEFAULT TRUE,
     friend_new_pin BOOLEAN DEFAULT TRUE,
     friend_request_received BOOLEAN DEFAULT TRUE,
-    friend_request_accepted BOOLEAN DEFAULT TRUE
+    friend_request_accepted BOOLEAN DEFAULT TRUE,
+    reaction_on_my_pin BOOLEAN DEFAULT TRUE
+);
+
+
+-- プライバシーゾーンテーブル (自宅・職場周辺のピンを他のユーザーから隠す円形の範囲)
+CREATE TABLE IF NOT EXISTS privacy_zones (
+    zone_id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    name VARCHAR(50) NOT NULL,
+    center GEOMETRY(Point, 4326) NOT NULL,
+    radius_meters DOUBLE PRECISION NOT NULL,
+    -- hide: 他のユーザーには表示しない / fuzz: 座標をぼかして表示する
+    mode VARCHAR(10) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+-- ピンの読み出し時に投稿者のゾーンを引くためのインデックス
+CREATE INDEX IF NOT EXISTS idx_privacy_zones_user ON privacy_zones (user_id);
+
+
+-- オーディエンスリストテーブル (「親しい友達」など、ピンの公開先としてユーザーが管理するリスト)
+CREATE TABLE IF NOT EXISTS audiences (
+    audience_id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    name VARCHAR(50) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_audiences_user ON audiences (user_id);
+
+CREATE TABLE IF NOT EXISTS audience_members (
+    audience_id UUID NOT NULL REFERENCES audiences(audience_id) ON DELETE CASCADE,
+    member_user_id UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    PRIMARY KEY (audience_id, member_user_id)
+);
+
+
+-- メディアテーブル (アップロードされた画像。ファイル本体は MediaStorage に保存する)
+CREATE TABLE IF NOT EXISTS media (
+    media_id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    content_type VARCHAR(50) NOT NULL,
+    width INTEGER NOT NULL,
+    height INTEGER NOT NULL,
+    size_bytes BIGINT NOT NULL,
+    storage_key TEXT NOT NULL,
+    thumbnail_key TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL
 );
 
 
@@ -30,8 +145,15 @@ CREATE TABLE IF NOT EXISTS pins (
     location GEOMETRY(Point, 4326) NOT NULL, 
     content_text TEXT NOT NULL,
     media_url TEXT,
+    -- public / friends / private / audience
     privacy_setting VARCHAR(10) NOT NULL,
+    -- privacy_setting が audience の場合の公開先（リスト削除後は投稿者のみが閲覧できる）
+    audience_id UUID REFERENCES audiences(audience_id) ON DELETE SET NULL,
     status VARCHAR(10) DEFAULT 'active',
+    -- GPSログなどから一括インポートされたピン（位置の整合性チェックの対象外）
+    is_imported BOOLEAN NOT NULL DEFAULT FALSE,
+    -- 一時的なピンの公開期限（NULL は無期限）。期限を過ぎるとスイーパーが status を expired にする
+    expires_at TIMESTAMP WITH TIME ZONE,
     created_at TIMESTAMP WITH TIME ZONE NOT NULL
 );
 
@@ -52,6 +174,17 @@ CREATE TABLE IF NOT EXISTS comments (
 CREATE INDEX IF NOT EXISTS idx_comments_pin_created ON comments (pin_id, created_at ASC);
 
 
+-- Pin Reactions (絵文字リアクション) テーブル
+CREATE TABLE IF NOT EXISTS pin_reactions (
+    pin_id UUID NOT NULL REFERENCES pins(pin_id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    emoji VARCHAR(32) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    -- 1ユーザーにつき絵文字ごとに1リアクション
+    PRIMARY KEY (pin_id, user_id, emoji)
+);
+
+
 -- Friends (フレンド関係) テーブル
 CREATE TABLE IF NOT EXISTS friends (
     user_a_id UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
@@ -80,4 +213,37 @@ CREATE TABLE IF NOT EXISTS notifications (
 );
 
 -- 受信者IDと作成日時の降順でソート検索を高速化する複合インデックス
-CREATE INDEX IF NOT EXISTS idx_notifications_recipient_created ON notifications (recipient_user_id, created_at DESC);
\ No newline at end of file
+CREATE INDEX IF NOT EXISTS idx_notifications_recipient_created ON notifications (recipient_user_id, created_at DESC);
+-- 未読件数の取得を高速化するための部分インデックス
+CREATE INDEX IF NOT EXISTS idx_notifications_recipient_unread ON notifications (recipient_user_id) WHERE is_read = FALSE;
+
+-- 既存のデータベースへの列追加（CREATE TABLE IF NOT EXISTS は作成済みのテーブルに列を追加しないため）
+ALTER TABLE pins ADD COLUMN IF NOT EXISTS is_imported BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE pins ADD COLUMN IF NOT EXISTS audience_id UUID REFERENCES audiences(audience_id) ON DELETE SET NULL;
+ALTER TABLE pins ADD COLUMN IF NOT EXISTS expires_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE user_settings ADD COLUMN IF NOT EXISTS reaction_on_my_pin BOOLEAN DEFAULT TRUE;
+
+-- メール確認の導入前に登録したユーザーは確認済みとして扱う（列を追加したときのみ実行する）
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_schema = current_schema() AND table_name = 'users' AND column_name = 'email_verified_at'
+    ) THEN
+        ALTER TABLE users ADD COLUMN email_verified_at TIMESTAMP WITH TIME ZONE;
+        UPDATE users SET email_verified_at = created_at;
+    END IF;
+END $$;
+
+
+-- 半径検索 (ST_DWithin on geography) を高速化するためのインデックス
+CREATE INDEX IF NOT EXISTS pins_location_geog_idx ON pins USING GIST ((location::geography));
+
+-- ユーザーごとのピン一覧（エクスポート・最新ピンの取得）を高速化するためのインデックス
+CREATE INDEX IF NOT EXISTS idx_pins_user_created ON pins (user_id, created_at);
+
+-- 期限切れピンのスイープを高速化するための部分インデックス
+CREATE INDEX IF NOT EXISTS idx_pins_active_expires ON pins (expires_at) WHERE status = 'active' AND expires_at IS NOT NULL;
+
+-- メディア配信時の公開範囲チェックで、メディアを添付したピンを引くためのインデックス
+CREATE INDEX IF NOT EXISTS idx_pins_media_url ON pins (media_url) WHERE media_url IS NOT NULL;